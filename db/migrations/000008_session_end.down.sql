@@ -0,0 +1,23 @@
+CREATE OR REPLACE FUNCTION session.f_before_update_check_time_in_campus() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.end_date_time <= OLD.end_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than previous value';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session.f_before_update_check_time_activity() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.end_date_time <= OLD.end_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than previous value';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE IF EXISTS session.activity
+    DROP COLUMN IF EXISTS end_reason;
+
+ALTER TABLE IF EXISTS session.in_campus
+    DROP COLUMN IF EXISTS end_reason;
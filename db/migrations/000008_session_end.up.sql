@@ -0,0 +1,37 @@
+ALTER TABLE IF EXISTS session.in_campus
+    ADD COLUMN IF NOT EXISTS end_reason VARCHAR(15);
+
+ALTER TABLE IF EXISTS session.activity
+    ADD COLUMN IF NOT EXISTS end_reason VARCHAR(15);
+
+-- closing a session (end_reason is set) may move end_date_time back,
+-- e.g. logout at 14:00 while the last ping already extended it to 14:01
+CREATE OR REPLACE FUNCTION session.f_before_update_check_time_in_campus() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.end_reason IS NOT NULL AND OLD.end_reason IS NULL THEN
+        IF NEW.end_date_time < NEW.start_date_time THEN
+            RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+        END IF;
+        RETURN NEW;
+    END IF;
+    IF NEW.end_date_time <= OLD.end_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than previous value';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session.f_before_update_check_time_activity() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.end_reason IS NOT NULL AND OLD.end_reason IS NULL THEN
+        IF NEW.end_date_time < NEW.start_date_time THEN
+            RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+        END IF;
+        RETURN NEW;
+    END IF;
+    IF NEW.end_date_time <= OLD.end_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than previous value';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS session.audit_log;
\ No newline at end of file
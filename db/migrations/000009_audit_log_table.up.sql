@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS session.audit_log (
+	id			BIGSERIAL PRIMARY KEY,
+	admin		VARCHAR(50) NOT NULL,
+	action		VARCHAR(30) NOT NULL,
+	target		VARCHAR(50),
+	comment		TEXT,
+	date_time	TIMESTAMP DEFAULT current_timestamp
+);
+
+ALTER TABLE IF EXISTS session.audit_log
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.audit_log TO session_manager;
+
+GRANT ALL ON TABLE session.audit_log TO postgres;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS session.commands;
\ No newline at end of file
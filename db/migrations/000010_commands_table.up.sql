@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS session.commands (
+	id				BIGSERIAL PRIMARY KEY,
+	comp_name		VARCHAR(30) REFERENCES session.computers(comp_name),
+	session_id		UUID REFERENCES session.in_campus(id),
+	command			VARCHAR(20) NOT NULL,
+	message			TEXT,
+	next_ping_sec	INT,
+	created_by		VARCHAR(50) NOT NULL,
+	created_at		TIMESTAMP DEFAULT current_timestamp,
+	delivered_at	TIMESTAMP,
+	acked_at		TIMESTAMP,
+	CONSTRAINT command_target CHECK (comp_name IS NOT NULL OR session_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS commands_pending_comp_name_idx
+    ON session.commands (comp_name) WHERE acked_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS commands_pending_session_id_idx
+    ON session.commands (session_id) WHERE acked_at IS NULL;
+
+ALTER TABLE IF EXISTS session.commands
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.commands TO session_manager;
+
+GRANT ALL ON TABLE session.commands TO postgres;
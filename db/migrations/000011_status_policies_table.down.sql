@@ -0,0 +1 @@
+DROP TABLE IF EXISTS session.status_policies;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- session policies keyed on public.users.status,
+-- users without status (or without policy for their status) get the 'default' policy
+CREATE TABLE IF NOT EXISTS session.status_policies (
+	status			VARCHAR(15) PRIMARY KEY,
+	max_sessions	INT NOT NULL DEFAULT 1, -- 0 means unlimited
+	comp_pattern	VARCHAR(100) -- regexp of allowed computer names, NULL means any
+);
+
+ALTER TABLE IF EXISTS session.status_policies
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.status_policies TO session_manager;
+
+GRANT ALL ON TABLE session.status_policies TO postgres;
+
+INSERT INTO session.status_policies (status, max_sessions)
+VALUES ('default', 1), ('staff', 2), ('mentor', 2)
+ON CONFLICT (status) DO NOTHING;
@@ -0,0 +1,8 @@
+ALTER TABLE IF EXISTS session.computers
+    DROP COLUMN IF EXISTS clock_skew_sec,
+    DROP COLUMN IF EXISTS clock_checked_at;
+
+ALTER TABLE IF EXISTS session.in_campus
+    DROP COLUMN IF EXISTS client_date_time,
+    DROP COLUMN IF EXISTS server_date_time,
+    DROP COLUMN IF EXISTS clock_skewed;
@@ -0,0 +1,10 @@
+-- last time reported by the computer and the time the request was received by the server
+ALTER TABLE IF EXISTS session.in_campus
+    ADD COLUMN IF NOT EXISTS client_date_time TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS server_date_time TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS clock_skewed BOOLEAN NOT NULL DEFAULT false;
+
+-- last observed skew of the computer clock (client - server)
+ALTER TABLE IF EXISTS session.computers
+    ADD COLUMN IF NOT EXISTS clock_skew_sec INT,
+    ADD COLUMN IF NOT EXISTS clock_checked_at TIMESTAMP;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS session.segments;
\ No newline at end of file
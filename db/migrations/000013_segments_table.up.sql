@@ -0,0 +1,32 @@
+-- continuous online intervals of a session (session_type = '') or of its activities,
+-- a ping after the previous end_date_time (plus tolerance) opens a new segment
+CREATE TABLE IF NOT EXISTS session.segments (
+	id				BIGSERIAL PRIMARY KEY,
+	session_id		UUID REFERENCES session.in_campus(id),
+	session_type	VARCHAR(20) NOT NULL DEFAULT '',
+	login			VARCHAR(50) REFERENCES public.users(login),
+	start_date_time	TIMESTAMP DEFAULT current_timestamp,
+	end_date_time	TIMESTAMP DEFAULT current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS segments_session_idx
+    ON session.segments (session_id, session_type, start_date_time);
+
+CREATE INDEX IF NOT EXISTS segments_login_idx
+    ON session.segments (login, session_type, start_date_time);
+
+ALTER TABLE IF EXISTS session.segments
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.segments TO session_manager;
+
+GRANT ALL ON TABLE session.segments TO postgres;
+
+-- existing sessions and activities become one segment each
+INSERT INTO session.segments (session_id, session_type, login, start_date_time, end_date_time)
+SELECT id, '', login, start_date_time, end_date_time
+FROM session.in_campus;
+
+INSERT INTO session.segments (session_id, session_type, login, start_date_time, end_date_time)
+SELECT session_id, COALESCE(session_type, ''), login, start_date_time, end_date_time
+FROM session.activity;
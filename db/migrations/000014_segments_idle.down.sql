@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS session.segments
+    DROP COLUMN IF EXISTS idle_sec,
+    DROP COLUMN IF EXISTS input_events;
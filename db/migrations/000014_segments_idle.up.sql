@@ -0,0 +1,4 @@
+-- reported by the computer agent: idle seconds and keyboard/mouse events since the previous ping
+ALTER TABLE IF EXISTS session.segments
+    ADD COLUMN IF NOT EXISTS idle_sec INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS input_events INT NOT NULL DEFAULT 0;
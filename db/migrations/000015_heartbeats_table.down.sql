@@ -0,0 +1,49 @@
+CREATE OR REPLACE FUNCTION session.f_before_insert_check_time_in_campus() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.end_date_time <= NEW.start_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session.f_before_update_check_time_in_campus() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.end_reason IS NOT NULL AND OLD.end_reason IS NULL THEN
+        IF NEW.end_date_time < NEW.start_date_time THEN
+            RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+        END IF;
+        RETURN NEW;
+    END IF;
+    IF NEW.end_date_time <= OLD.end_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than previous value';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session.f_before_insert_check_time_activity() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.end_date_time <= NEW.start_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session.f_before_update_check_time_activity() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.end_reason IS NOT NULL AND OLD.end_reason IS NULL THEN
+        IF NEW.end_date_time < NEW.start_date_time THEN
+            RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+        END IF;
+        RETURN NEW;
+    END IF;
+    IF NEW.end_date_time <= OLD.end_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than previous value';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS session.heartbeats;
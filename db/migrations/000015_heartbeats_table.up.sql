@@ -0,0 +1,95 @@
+-- append-only log of every notification from computers,
+-- used for audit and to recompute in_campus, activity and segments (see /admin/rebuild)
+CREATE TABLE IF NOT EXISTS session.heartbeats (
+	id					BIGSERIAL PRIMARY KEY,
+	session_id			UUID REFERENCES session.in_campus(id),
+	type				VARCHAR(10) NOT NULL, -- session, activity, resume, end
+	session_type		VARCHAR(20) NOT NULL DEFAULT '',
+	login				VARCHAR(50),
+	comp_name			VARCHAR(30),
+	ip_addr				VARCHAR(20),
+	next_ping_sec		INT,
+	date_time			TIMESTAMP, -- accepted time (after clock policy)
+	client_date_time	TIMESTAMP,
+	server_date_time	TIMESTAMP,
+	idle_sec			INT NOT NULL DEFAULT 0,
+	input_events		INT NOT NULL DEFAULT 0,
+	end_reason			VARCHAR(15)
+);
+
+CREATE INDEX IF NOT EXISTS heartbeats_session_idx
+    ON session.heartbeats (session_id, id);
+
+CREATE INDEX IF NOT EXISTS heartbeats_date_time_idx
+    ON session.heartbeats (date_time) WHERE type = 'session';
+
+ALTER TABLE IF EXISTS session.heartbeats
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.heartbeats TO session_manager;
+
+GRANT ALL ON TABLE session.heartbeats TO postgres;
+
+CREATE OR REPLACE FUNCTION session.f_before_insert_check_time_in_campus() RETURNS TRIGGER AS $$
+BEGIN
+    -- replay of the heartbeat log writes recomputed values as is
+    IF current_setting('session_manager.rebuild', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    IF NEW.end_date_time <= NEW.start_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session.f_before_update_check_time_in_campus() RETURNS TRIGGER AS $$
+BEGIN
+    -- replay of the heartbeat log writes recomputed values as is
+    IF current_setting('session_manager.rebuild', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    IF NEW.end_reason IS NOT NULL AND OLD.end_reason IS NULL THEN
+        IF NEW.end_date_time < NEW.start_date_time THEN
+            RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+        END IF;
+        RETURN NEW;
+    END IF;
+    IF NEW.end_date_time <= OLD.end_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than previous value';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session.f_before_insert_check_time_activity() RETURNS TRIGGER AS $$
+BEGIN
+    -- replay of the heartbeat log writes recomputed values as is
+    IF current_setting('session_manager.rebuild', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    IF NEW.end_date_time <= NEW.start_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION session.f_before_update_check_time_activity() RETURNS TRIGGER AS $$
+BEGIN
+    -- replay of the heartbeat log writes recomputed values as is
+    IF current_setting('session_manager.rebuild', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    IF NEW.end_reason IS NOT NULL AND OLD.end_reason IS NULL THEN
+        IF NEW.end_date_time < NEW.start_date_time THEN
+            RAISE EXCEPTION 'end_date_time must be greater than start_date_time';
+        END IF;
+        RETURN NEW;
+    END IF;
+    IF NEW.end_date_time <= OLD.end_date_time THEN
+        RAISE EXCEPTION 'end_date_time must be greater than previous value';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
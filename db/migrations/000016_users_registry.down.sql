@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS public.users_login_pattern_idx;
+
+ALTER TABLE IF EXISTS public.users
+    ALTER COLUMN status DROP DEFAULT,
+    DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,9 @@
+UPDATE public.users SET status = 'active' WHERE status IS NULL;
+
+ALTER TABLE IF EXISTS public.users
+    ALTER COLUMN status SET DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP; -- soft delete
+
+-- prefix search
+CREATE INDEX IF NOT EXISTS users_login_pattern_idx
+    ON public.users (login varchar_pattern_ops);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS session.computers_comp_name_pattern_idx;
+
+ALTER TABLE IF EXISTS session.computers
+    ALTER COLUMN status DROP DEFAULT;
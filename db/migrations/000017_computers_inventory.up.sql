@@ -0,0 +1,8 @@
+UPDATE session.computers SET status = 'active' WHERE status IS NULL;
+
+ALTER TABLE IF EXISTS session.computers
+    ALTER COLUMN status SET DEFAULT 'active';
+
+-- prefix search
+CREATE INDEX IF NOT EXISTS computers_comp_name_pattern_idx
+    ON session.computers (comp_name varchar_pattern_ops);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS session.computers_location_idx;
+
+ALTER TABLE IF EXISTS session.computers
+    DROP COLUMN IF EXISTS building,
+    DROP COLUMN IF EXISTS floor,
+    DROP COLUMN IF EXISTS zone,
+    DROP COLUMN IF EXISTS seat_row,
+    DROP COLUMN IF EXISTS seat,
+    DROP COLUMN IF EXISTS pos_x,
+    DROP COLUMN IF EXISTS pos_y;
@@ -0,0 +1,11 @@
+ALTER TABLE IF EXISTS session.computers
+    ADD COLUMN IF NOT EXISTS building VARCHAR(30),
+    ADD COLUMN IF NOT EXISTS floor VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS zone VARCHAR(30),
+    ADD COLUMN IF NOT EXISTS seat_row VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS seat VARCHAR(10),
+    ADD COLUMN IF NOT EXISTS pos_x DOUBLE PRECISION, -- coordinates on the floor map
+    ADD COLUMN IF NOT EXISTS pos_y DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS computers_location_idx
+    ON session.computers (building, floor);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS session.zones;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- zones group computers by the name pattern,
+-- explicit session.computers.zone has priority over the pattern
+CREATE TABLE IF NOT EXISTS session.zones (
+	name			VARCHAR(30) PRIMARY KEY,
+	comp_pattern	VARCHAR(100), -- regexp of computer names, NULL means explicit only
+	priority		INT NOT NULL DEFAULT 0 -- first matched pattern by priority wins
+);
+
+ALTER TABLE IF EXISTS session.zones
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.zones TO session_manager;
+
+GRANT ALL ON TABLE session.zones TO postgres;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS session.cohort_members;
+
+DROP TABLE IF EXISTS session.cohorts;
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- cohorts of users, e.g. intake '2023-09' or 'piscine-go',
+-- a user can be a member of several cohorts
+CREATE TABLE IF NOT EXISTS session.cohorts (
+	name		VARCHAR(30) PRIMARY KEY,
+	description	VARCHAR(100),
+	created_at	TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE IF EXISTS session.cohorts
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.cohorts TO session_manager;
+
+GRANT ALL ON TABLE session.cohorts TO postgres;
+
+CREATE TABLE IF NOT EXISTS session.cohort_members (
+	cohort	VARCHAR(30) NOT NULL REFERENCES session.cohorts (name) ON DELETE CASCADE,
+	login	VARCHAR(50) NOT NULL REFERENCES public.users (login),
+	PRIMARY KEY (cohort, login)
+);
+
+ALTER TABLE IF EXISTS session.cohort_members
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.cohort_members TO session_manager;
+
+GRANT ALL ON TABLE session.cohort_members TO postgres;
+
+CREATE INDEX IF NOT EXISTS cohort_members_login_idx
+    ON session.cohort_members (login);
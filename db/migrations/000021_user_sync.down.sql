@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS session.user_syncs;
+
+ALTER TABLE IF EXISTS public.users
+    DROP COLUMN IF EXISTS removed_at;
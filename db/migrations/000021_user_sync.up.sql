@@ -0,0 +1,24 @@
+ALTER TABLE IF EXISTS public.users
+    ADD COLUMN IF NOT EXISTS removed_at TIMESTAMP; -- not found in the user directory
+
+-- reports of the user directory sync
+CREATE TABLE IF NOT EXISTS session.user_syncs (
+	id			BIGSERIAL PRIMARY KEY,
+	source		VARCHAR(200) NOT NULL,
+	started_at	TIMESTAMP NOT NULL,
+	finished_at	TIMESTAMP NOT NULL DEFAULT NOW(),
+	total		INT NOT NULL DEFAULT 0, -- users in the directory
+	invalid		INT NOT NULL DEFAULT 0, -- skipped users of the directory
+	created		TEXT[] NOT NULL DEFAULT '{}',
+	updated		TEXT[] NOT NULL DEFAULT '{}',
+	removed		TEXT[] NOT NULL DEFAULT '{}',
+	restored	TEXT[] NOT NULL DEFAULT '{}',
+	error		TEXT
+);
+
+ALTER TABLE IF EXISTS session.user_syncs
+    OWNER to postgres;
+
+GRANT ALL ON TABLE session.user_syncs TO session_manager;
+
+GRANT ALL ON TABLE session.user_syncs TO postgres;
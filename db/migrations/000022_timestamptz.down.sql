@@ -0,0 +1,41 @@
+-- back to the wall time of the TimeZone setting of the migrating connection (CAMPUS_TIMEZONE)
+ALTER TABLE IF EXISTS session.in_campus
+    ALTER COLUMN start_date_time TYPE TIMESTAMP USING start_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN end_date_time TYPE TIMESTAMP USING end_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN client_date_time TYPE TIMESTAMP USING client_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN server_date_time TYPE TIMESTAMP USING server_date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.activity
+    ALTER COLUMN start_date_time TYPE TIMESTAMP USING start_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN end_date_time TYPE TIMESTAMP USING end_date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.segments
+    ALTER COLUMN start_date_time TYPE TIMESTAMP USING start_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN end_date_time TYPE TIMESTAMP USING end_date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.heartbeats
+    ALTER COLUMN date_time TYPE TIMESTAMP USING date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN client_date_time TYPE TIMESTAMP USING client_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN server_date_time TYPE TIMESTAMP USING server_date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.audit_log
+    ALTER COLUMN date_time TYPE TIMESTAMP USING date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.commands
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN delivered_at TYPE TIMESTAMP USING delivered_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN acked_at TYPE TIMESTAMP USING acked_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.computers
+    ALTER COLUMN clock_checked_at TYPE TIMESTAMP USING clock_checked_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS public.users
+    ALTER COLUMN deleted_at TYPE TIMESTAMP USING deleted_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN removed_at TYPE TIMESTAMP USING removed_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.cohorts
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.user_syncs
+    ALTER COLUMN started_at TYPE TIMESTAMP USING started_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN finished_at TYPE TIMESTAMP USING finished_at AT TIME ZONE current_setting('TimeZone');
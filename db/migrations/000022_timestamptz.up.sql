@@ -0,0 +1,47 @@
+-- all times become instants. Existing values have no zone, they are read as the wall time
+-- of the TimeZone setting of the migrating connection: set it to CAMPUS_TIMEZONE before migrating,
+-- e.g. ALTER DATABASE db_name SET timezone = 'Asia/Almaty' or &timezone=Asia/Almaty in the DATABASE_URL.
+-- The old SET TIME ZONE changed only one pooled connection, so the values were written as is:
+-- NOW() defaults as the wall time of the database TimeZone, server times as the wall time of the server,
+-- client RFC3339 times as the wall time of the client (the offset was dropped).
+-- They are right only if all of them ran in the campus timezone, other rows have to be corrected by hand
+ALTER TABLE IF EXISTS session.in_campus
+    ALTER COLUMN start_date_time TYPE TIMESTAMPTZ USING start_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN end_date_time TYPE TIMESTAMPTZ USING end_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN client_date_time TYPE TIMESTAMPTZ USING client_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN server_date_time TYPE TIMESTAMPTZ USING server_date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.activity
+    ALTER COLUMN start_date_time TYPE TIMESTAMPTZ USING start_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN end_date_time TYPE TIMESTAMPTZ USING end_date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.segments
+    ALTER COLUMN start_date_time TYPE TIMESTAMPTZ USING start_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN end_date_time TYPE TIMESTAMPTZ USING end_date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.heartbeats
+    ALTER COLUMN date_time TYPE TIMESTAMPTZ USING date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN client_date_time TYPE TIMESTAMPTZ USING client_date_time AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN server_date_time TYPE TIMESTAMPTZ USING server_date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.audit_log
+    ALTER COLUMN date_time TYPE TIMESTAMPTZ USING date_time AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.commands
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN delivered_at TYPE TIMESTAMPTZ USING delivered_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN acked_at TYPE TIMESTAMPTZ USING acked_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.computers
+    ALTER COLUMN clock_checked_at TYPE TIMESTAMPTZ USING clock_checked_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS public.users
+    ALTER COLUMN deleted_at TYPE TIMESTAMPTZ USING deleted_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN removed_at TYPE TIMESTAMPTZ USING removed_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.cohorts
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE IF EXISTS session.user_syncs
+    ALTER COLUMN started_at TYPE TIMESTAMPTZ USING started_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN finished_at TYPE TIMESTAMPTZ USING finished_at AT TIME ZONE current_setting('TimeZone');
@@ -0,0 +1 @@
+-- bound sessions are kept: commands addressed to the session are delivered the same way
@@ -0,0 +1,14 @@
+-- commands addressed to a computer belong to the session active when they were queued,
+-- pending ones without it are bound to the session of the computer open at created_at
+UPDATE session.commands c
+SET session_id = (
+    SELECT s.id
+    FROM session.in_campus s
+    WHERE s.comp_name = c.comp_name
+        AND s.start_date_time <= c.created_at
+        AND s.end_date_time >= c.created_at
+    ORDER BY s.start_date_time DESC
+    LIMIT 1
+)
+WHERE c.session_id IS NULL
+    AND c.acked_at IS NULL;
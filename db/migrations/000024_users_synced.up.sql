@@ -0,0 +1,4 @@
+-- last time the user was found in the directory, NULL - the user is not from the directory
+-- (e.g. created by hand) and is never flagged as removed by the sync
+ALTER TABLE IF EXISTS public.users
+    ADD COLUMN IF NOT EXISTS synced_at TIMESTAMPTZ;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS session.segments_type_start_idx;
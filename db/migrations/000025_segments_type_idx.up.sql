@@ -0,0 +1,3 @@
+-- campus-wide reports (leaderboard) filter segments by type and date range
+CREATE INDEX IF NOT EXISTS segments_type_start_idx
+    ON session.segments (session_type, start_date_time);
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handlers) CreateCohort(c echo.Context) error {
+	var req request.Cohort
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("CreateCohort: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("CreateCohort: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	if err := h.svc.CreateCohort(c.Request().Context(), dto); err != nil {
+		c.Set(logErr, fmt.Sprintf("CreateCohort: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusCreated, response.Data{
+		Message: http.StatusText(http.StatusCreated)},
+	)
+}
+
+func (h *handlers) GetCohorts(c echo.Context) error {
+	defer printLogErr(c)
+
+	cohorts, err := h.svc.GetCohorts(c.Request().Context())
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetCohorts: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    cohorts,
+	})
+}
+
+func (h *handlers) GetCohort(c echo.Context) error {
+	defer printLogErr(c)
+
+	cohort, err := h.svc.GetCohort(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetCohort: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    cohort,
+	})
+}
+
+func (h *handlers) DeleteCohort(c echo.Context) error {
+	defer printLogErr(c)
+
+	if err := h.svc.DeleteCohort(c.Request().Context(), c.Param("name")); err != nil {
+		c.Set(logErr, fmt.Sprintf("DeleteCohort: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
+	)
+}
+
+func (h *handlers) AddCohortMembers(c echo.Context) error {
+	var req request.CohortMembers
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("AddCohortMembers: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("AddCohortMembers: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	members, err := h.svc.AddCohortMembers(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("AddCohortMembers: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    members,
+	})
+}
+
+func (h *handlers) RemoveCohortMembers(c echo.Context) error {
+	var req request.CohortMembers
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("RemoveCohortMembers: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("RemoveCohortMembers: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	members, err := h.svc.RemoveCohortMembers(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("RemoveCohortMembers: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    members,
+	})
+}
+
+func (h *handlers) GetCohortActivity(c echo.Context) error {
+	var req request.CohortActivity
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("GetCohortActivity: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetCohortActivity: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	activity, err := h.svc.GetCohortActivity(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetCohortActivity: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    activity,
+	})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handlers) GetComputers(c echo.Context) error {
+	var req request.ComputerFilter
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("GetComputers: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetComputers: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	computers, err := h.svc.GetComputers(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetComputers: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    computers,
+	})
+}
+
+func (h *handlers) GetComputer(c echo.Context) error {
+	defer printLogErr(c)
+
+	computer, err := h.svc.GetComputer(c.Request().Context(), c.Param("name"))
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetComputer: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    computer,
+	})
+}
+
+func (h *handlers) UpdateComputer(c echo.Context) error {
+	var req request.ComputerUpdate
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("UpdateComputer: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("UpdateComputer: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	if err := h.svc.UpdateComputer(c.Request().Context(), dto); err != nil {
+		c.Set(logErr, fmt.Sprintf("UpdateComputer: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
+	)
+}
+
+func (h *handlers) RetireComputer(c echo.Context) error {
+	defer printLogErr(c)
+
+	if err := h.svc.RetireComputer(c.Request().Context(), c.Param("name")); err != nil {
+		c.Set(logErr, fmt.Sprintf("RetireComputer: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
+	)
+}
+
+func (h *handlers) GetFloorMap(c echo.Context) error {
+	var req request.FloorFilter
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("GetFloorMap: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetFloorMap: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	floorMap, err := h.svc.GetFloorMap(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetFloorMap: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    floorMap,
+	})
+}
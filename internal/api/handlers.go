@@ -9,6 +9,7 @@ import (
 	"session_manager/internal/domain/request"
 	"session_manager/internal/domain/response"
 	"session_manager/internal/service"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -18,23 +19,60 @@ var logErr = "logErr"
 type Handlers interface {
 	CreateUsers(c echo.Context) error
 	CreateComputers(c echo.Context) error
+	GetUsers(c echo.Context) error
+	GetUser(c echo.Context) error
+	UpdateUser(c echo.Context) error
+	DeleteUser(c echo.Context) error
+	SyncUsers(c echo.Context) error
+	GetUserSyncs(c echo.Context) error
+	GetComputers(c echo.Context) error
+	GetComputer(c echo.Context) error
+	UpdateComputer(c echo.Context) error
+	RetireComputer(c echo.Context) error
+	GetFloorMap(c echo.Context) error
 	CreateSession(c echo.Context) error
 	CreateActivity(c echo.Context) error
+	EndSession(c echo.Context) error
+	TerminateSessions(c echo.Context) error
+	CreateCommand(c echo.Context) error
+	GetCommands(c echo.Context) error
+	GetSessionPolicies(c echo.Context) error
+	SetSessionPolicy(c echo.Context) error
+	GetZones(c echo.Context) error
+	SetZone(c echo.Context) error
+	DeleteZone(c echo.Context) error
+	GetZoneDashboard(c echo.Context) error
+	GetClockSkews(c echo.Context) error
+	RebuildSessions(c echo.Context) error
 	GetOnlineSessions(c echo.Context) error
 	GetUserActivity(c echo.Context) error
+	GetLeaderboard(c echo.Context) error
+	CreateCohort(c echo.Context) error
+	GetCohorts(c echo.Context) error
+	GetCohort(c echo.Context) error
+	DeleteCohort(c echo.Context) error
+	AddCohortMembers(c echo.Context) error
+	RemoveCohortMembers(c echo.Context) error
+	GetCohortActivity(c echo.Context) error
 }
 
 type handlers struct {
 	svc service.Service
+	loc *time.Location // campus timezone, date-times without offset are in it
 }
 
-func NewHandlers(logg echo.Logger, svc service.Service) Handlers {
+func NewHandlers(logg echo.Logger, svc service.Service, loc *time.Location) Handlers {
 	return &handlers{
 		svc: svc,
+		loc: loc,
 	}
 }
 
 func (h *handlers) CreateUsers(c echo.Context) error {
+	if isCSV(c) {
+		return h.importUsers(c)
+	}
+
 	var req []request.User
 
 	defer printLogErr(c)
@@ -46,17 +84,23 @@ func (h *handlers) CreateUsers(c echo.Context) error {
 	}
 
 	// create users
-	if err := h.svc.CreateUsers(c.Request().Context(), req); err != nil {
+	results, err := h.svc.CreateUsers(c.Request().Context(), req)
+	if err != nil {
 		c.Set(logErr, fmt.Sprintf("CreateUsers: %s", err))
 		return customErrResponse(c, err, nil)
 	}
 
 	return c.JSON(http.StatusCreated, response.Data{
-		Message: http.StatusText(http.StatusCreated)},
-	)
+		Message: http.StatusText(http.StatusCreated),
+		Data:    results,
+	})
 }
 
 func (h *handlers) CreateComputers(c echo.Context) error {
+	if isCSV(c) {
+		return h.importComputers(c)
+	}
+
 	var req []request.Computer
 
 	defer printLogErr(c)
@@ -68,14 +112,16 @@ func (h *handlers) CreateComputers(c echo.Context) error {
 	}
 
 	// create users
-	if err := h.svc.CreateComputers(c.Request().Context(), req); err != nil {
+	results, err := h.svc.CreateComputers(c.Request().Context(), req)
+	if err != nil {
 		c.Set(logErr, fmt.Sprintf("CreateComputers: %s", err))
 		return customErrResponse(c, err, nil)
 	}
 
 	return c.JSON(http.StatusCreated, response.Data{
-		Message: http.StatusText(http.StatusCreated)},
-	)
+		Message: http.StatusText(http.StatusCreated),
+		Data:    results,
+	})
 }
 
 func (h *handlers) CreateSession(c echo.Context) error {
@@ -90,21 +136,31 @@ func (h *handlers) CreateSession(c echo.Context) error {
 	}
 
 	// validate data
-	dto, err := req.Validate()
+	dto, err := req.Validate(h.loc)
 	if err != nil {
 		c.Set(logErr, fmt.Sprintf("CreateSession: validate: %s", err))
 		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
 	}
 
 	// create session
-	if sess, err := h.svc.CreateSession(c.Request().Context(), dto); err != nil {
+	start, sess, err := h.svc.CreateSession(c.Request().Context(), dto)
+	if err != nil {
 		c.Set(logErr, fmt.Sprintf("CreateSession: %s", err))
 		return customErrResponse(c, err, sess)
 	}
 
+	// previous session is resumed, agent must continue with its id
+	if start.Resumed {
+		return c.JSON(http.StatusOK, response.Data{
+			Message: http.StatusText(http.StatusOK),
+			Data:    start,
+		})
+	}
+
 	return c.JSON(http.StatusCreated, response.Data{
-		Message: http.StatusText(http.StatusCreated)},
-	)
+		Message: http.StatusText(http.StatusCreated),
+		Data:    start,
+	})
 }
 
 func (h *handlers) CreateActivity(c echo.Context) error {
@@ -119,23 +175,253 @@ func (h *handlers) CreateActivity(c echo.Context) error {
 	}
 
 	// validate data
-	dto, err := req.Validate()
+	dto, err := req.Validate(h.loc)
 	if err != nil {
 		c.Set(logErr, fmt.Sprintf("CreateActivity: validate: %s", err))
 		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
 	}
+	dto.IPAddress = c.RealIP()
 
 	// create activity
-	if err := h.svc.CreateActivity(c.Request().Context(), dto); err != nil {
+	heartbeat, err := h.svc.CreateActivity(c.Request().Context(), dto)
+	if err != nil {
 		c.Set(logErr, fmt.Sprintf("CreateActivity: %s", err))
 		return customErrResponse(c, err, nil)
 	}
 
 	return c.JSON(http.StatusCreated, response.Data{
-		Message: http.StatusText(http.StatusCreated)},
+		Message: http.StatusText(http.StatusCreated),
+		Data:    heartbeat,
+	})
+}
+
+func (h *handlers) EndSession(c echo.Context) error {
+	var req request.SessionEnd
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("EndSession: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate(h.loc)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("EndSession: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// end session
+	if err := h.svc.EndSession(c.Request().Context(), dto); err != nil {
+		c.Set(logErr, fmt.Sprintf("EndSession: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
 	)
 }
 
+func (h *handlers) TerminateSessions(c echo.Context) error {
+	var req request.SessionTerminate
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("TerminateSessions: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+	bindAdmin(c, &req.Admin)
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("TerminateSessions: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// terminate sessions
+	sessions, err := h.svc.TerminateSessions(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("TerminateSessions: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    sessions,
+	})
+}
+
+func (h *handlers) CreateCommand(c echo.Context) error {
+	var req request.Command
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("CreateCommand: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+	bindAdmin(c, &req.Admin)
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("CreateCommand: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// enqueue command
+	id, err := h.svc.CreateCommand(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("CreateCommand: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusCreated, response.Data{
+		Message: http.StatusText(http.StatusCreated),
+		Data:    map[string]int64{"id": id},
+	})
+}
+
+func (h *handlers) GetCommands(c echo.Context) error {
+	var req request.CommandFilter
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("GetCommands: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetCommands: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	commands, err := h.svc.GetCommands(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetCommands: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    commands,
+	})
+}
+
+func (h *handlers) GetSessionPolicies(c echo.Context) error {
+	defer printLogErr(c)
+
+	policies, err := h.svc.GetSessionPolicies(c.Request().Context())
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetSessionPolicies: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    policies,
+	})
+}
+
+func (h *handlers) SetSessionPolicy(c echo.Context) error {
+	var req request.SessionPolicy
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("SetSessionPolicy: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("SetSessionPolicy: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	if err := h.svc.SetSessionPolicy(c.Request().Context(), dto); err != nil {
+		c.Set(logErr, fmt.Sprintf("SetSessionPolicy: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
+	)
+}
+
+func (h *handlers) GetClockSkews(c echo.Context) error {
+	var req request.ClockSkewFilter
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("GetClockSkews: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetClockSkews: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	computers, err := h.svc.GetClockSkews(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetClockSkews: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    computers,
+	})
+}
+
+func (h *handlers) RebuildSessions(c echo.Context) error {
+	var req request.Rebuild
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("RebuildSessions: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+	bindAdmin(c, &req.Admin)
+
+	// validate data
+	dto, err := req.Validate(h.loc)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("RebuildSessions: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	report, err := h.svc.RebuildSessions(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("RebuildSessions: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    report,
+	})
+}
+
 func (h *handlers) GetOnlineSessions(c echo.Context) error {
 	defer printLogErr(c)
 
@@ -181,6 +467,36 @@ func (h *handlers) GetUserActivity(c echo.Context) (err error) {
 	})
 }
 
+func (h *handlers) GetLeaderboard(c echo.Context) error {
+	var req request.Leaderboard
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("GetLeaderboard: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetLeaderboard: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	leaderboard, err := h.svc.GetLeaderboard(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetLeaderboard: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    leaderboard,
+	})
+}
+
 func customErrResponse(c echo.Context, err error, data any) error {
 	if data == nil {
 		data = []string{} // to show empty array
@@ -191,6 +507,13 @@ func customErrResponse(c echo.Context, err error, data any) error {
 			Data:    data,
 		})
 	}
+	var errForbidden *response.ErrForbidden
+	if errors.As(err, &errForbidden) {
+		return c.JSON(http.StatusForbidden, response.Data{
+			Message: err.Error(),
+			Data:    map[string]string{"reason": errForbidden.Reason},
+		})
+	}
 	var errBadReq *response.ErrBadReq
 	if errors.As(err, &errBadReq) {
 		return c.JSON(http.StatusBadRequest, response.Data{
@@ -202,6 +525,17 @@ func customErrResponse(c echo.Context, err error, data any) error {
 	return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
 }
 
+// adminHeader is set by the reverse proxy to the authenticated user,
+// "admin" of the body is not authenticated and is used only without the header
+const adminHeader = "X-Forwarded-User"
+
+// bindAdmin replaces the admin of the request with the authenticated one
+func bindAdmin(c echo.Context, admin *string) {
+	if user := c.Request().Header.Get(adminHeader); user != "" {
+		*admin = user
+	}
+}
+
 func printLogErr(c echo.Context) {
 	if eLog := c.Get(logErr); eLog != nil {
 		log.Printf("\n//----\n[error]: %v\n----\\\\\n", eLog)
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const mimeTextCSV = "text/csv"
+
+// importErrResponse returns the error with results of the rows committed before it
+func importErrResponse(c echo.Context, err error, results *response.ImportResults) error {
+	if results == nil {
+		return customErrResponse(c, err, nil)
+	}
+	status := http.StatusInternalServerError
+	var errBadReq *response.ErrBadReq
+	if errors.As(err, &errBadReq) {
+		status = http.StatusBadRequest
+	}
+	return c.JSON(status, response.Data{Message: err.Error(), Data: results})
+}
+
+// isCSV reports if the body is csv file: raw or the file of multipart form
+func isCSV(c echo.Context) bool {
+	ctype := c.Request().Header.Get(echo.HeaderContentType)
+	return strings.HasPrefix(ctype, mimeTextCSV) || strings.HasPrefix(ctype, echo.MIMEMultipartForm)
+}
+
+// csvBody returns the body or the first file of multipart form without reading it in memory
+func csvBody(c echo.Context) (io.Reader, error) {
+	ctype := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(ctype, echo.MIMEMultipartForm) {
+		return c.Request().Body, nil
+	}
+
+	mr, err := c.Request().MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("multipart form has no file")
+			}
+			return nil, err
+		}
+		if part.FileName() != "" {
+			return part, nil
+		}
+	}
+}
+
+func (h *handlers) importUsers(c echo.Context) error {
+	var req request.Import
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportUsers: bind req query: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate(request.UserColumns)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportUsers: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	body, err := csvBody(c)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportUsers: body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	reader, err := request.NewCSVReader(body, dto, request.UserColumns)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportUsers: csv: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	results, err := h.svc.ImportUsers(c.Request().Context(), dto, reader)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportUsers: %s", err))
+		return importErrResponse(c, err, results)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    results,
+	})
+}
+
+func (h *handlers) importComputers(c echo.Context) error {
+	var req request.Import
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportComputers: bind req query: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate(request.ComputerColumns)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportComputers: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	body, err := csvBody(c)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportComputers: body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	reader, err := request.NewCSVReader(body, dto, request.ComputerColumns)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportComputers: csv: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	results, err := h.svc.ImportComputers(c.Request().Context(), dto, reader)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("ImportComputers: %s", err))
+		return importErrResponse(c, err, results)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    results,
+	})
+}
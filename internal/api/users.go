@@ -0,0 +1,126 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handlers) GetUsers(c echo.Context) error {
+	var req request.UserFilter
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("GetUsers: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetUsers: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	users, err := h.svc.GetUsers(c.Request().Context(), dto)
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetUsers: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    users,
+	})
+}
+
+func (h *handlers) GetUser(c echo.Context) error {
+	defer printLogErr(c)
+
+	user, err := h.svc.GetUser(c.Request().Context(), c.Param("login"))
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetUser: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    user,
+	})
+}
+
+func (h *handlers) UpdateUser(c echo.Context) error {
+	var req request.UserUpdate
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("UpdateUser: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("UpdateUser: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	if err := h.svc.UpdateUser(c.Request().Context(), dto); err != nil {
+		c.Set(logErr, fmt.Sprintf("UpdateUser: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
+	)
+}
+
+func (h *handlers) DeleteUser(c echo.Context) error {
+	defer printLogErr(c)
+
+	if err := h.svc.DeleteUser(c.Request().Context(), c.Param("login")); err != nil {
+		c.Set(logErr, fmt.Sprintf("DeleteUser: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
+	)
+}
+
+func (h *handlers) SyncUsers(c echo.Context) error {
+	defer printLogErr(c)
+
+	report, err := h.svc.SyncUsers(c.Request().Context())
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("SyncUsers: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    report,
+	})
+}
+
+func (h *handlers) GetUserSyncs(c echo.Context) error {
+	defer printLogErr(c)
+
+	reports, err := h.svc.GetUserSyncs(c.Request().Context())
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetUserSyncs: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    reports,
+	})
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handlers) GetZones(c echo.Context) error {
+	defer printLogErr(c)
+
+	zones, err := h.svc.GetZones(c.Request().Context())
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetZones: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    zones,
+	})
+}
+
+func (h *handlers) SetZone(c echo.Context) error {
+	var req request.Zone
+
+	defer printLogErr(c)
+
+	// parse data
+	if err := c.Bind(&req); err != nil {
+		c.Set(logErr, fmt.Sprintf("SetZone: bind req body: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	// validate data
+	dto, err := req.Validate()
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("SetZone: validate: %s", err))
+		return c.JSON(http.StatusBadRequest, response.Data{Message: err.Error()})
+	}
+
+	if err := h.svc.SetZone(c.Request().Context(), dto); err != nil {
+		c.Set(logErr, fmt.Sprintf("SetZone: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
+	)
+}
+
+func (h *handlers) DeleteZone(c echo.Context) error {
+	defer printLogErr(c)
+
+	if err := h.svc.DeleteZone(c.Request().Context(), c.Param("name")); err != nil {
+		c.Set(logErr, fmt.Sprintf("DeleteZone: %s", err))
+		return customErrResponse(c, err, nil)
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: http.StatusText(http.StatusOK)},
+	)
+}
+
+func (h *handlers) GetZoneDashboard(c echo.Context) error {
+	defer printLogErr(c)
+
+	zones, err := h.svc.GetZoneDashboard(c.Request().Context())
+	if err != nil {
+		c.Set(logErr, fmt.Sprintf("GetZoneDashboard: %s", err))
+		return c.JSON(http.StatusInternalServerError, response.Data{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, response.Data{
+		Message: "Success",
+		Data:    zones,
+	})
+}
@@ -0,0 +1,55 @@
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"session_manager/internal/domain"
+)
+
+// HTTPSource gets users from JSON array: [{"login": "user_1", "status": "active"}, ...]
+type HTTPSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (hs *HTTPSource) Name() string { return hs.URL }
+
+func (hs *HTTPSource) Users(ctx context.Context) ([]domain.DirectoryUser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hs.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := hs.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("response status %d: %s", resp.StatusCode, body)
+	}
+
+	var users []struct {
+		Login  string `json:"login"`
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&users); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	dirUsers := make([]domain.DirectoryUser, 0, len(users))
+	for _, user := range users {
+		dirUsers = append(dirUsers, domain.DirectoryUser{
+			Login:  user.Login,
+			Status: user.Status,
+		})
+	}
+
+	return dirUsers, nil
+}
@@ -0,0 +1,97 @@
+package directory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"session_manager/internal/domain"
+	"testing"
+)
+
+func TestHTTPSourceUsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    []domain.DirectoryUser
+		wantErr bool
+	}{
+		{
+			name:   "users",
+			status: http.StatusOK,
+			body:   `[{"login": "user_1", "status": "active"}, {"login": "user_2", "name": "Aigerim"}]`,
+			want: []domain.DirectoryUser{
+				{Login: "user_1", Status: "active"},
+				{Login: "user_2"},
+			},
+		},
+		{
+			name:   "empty",
+			status: http.StatusOK,
+			body:   `[]`,
+			want:   []domain.DirectoryUser{},
+		},
+		{
+			name:    "error status",
+			status:  http.StatusServiceUnavailable,
+			body:    `maintenance`,
+			wantErr: true,
+		},
+		{
+			name:    "not an array",
+			status:  http.StatusOK,
+			body:    `{"users": []}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("want GET, got %s", r.Method)
+				}
+				if accept := r.Header.Get("Accept"); accept != "application/json" {
+					t.Errorf("want Accept application/json, got %q", accept)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			source := NewSource(server.URL, "uid", "")
+			if _, ok := source.(*HTTPSource); !ok {
+				t.Fatalf("want HTTPSource for %s, got %T", server.URL, source)
+			}
+
+			users, err := source.Users(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %v", users)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(users, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, users)
+			}
+		})
+	}
+}
+
+func TestHTTPSourceCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewSource(server.URL, "uid", "").Users(ctx); err == nil {
+		t.Fatal("want error for canceled context")
+	}
+}
@@ -0,0 +1,107 @@
+package directory
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"session_manager/internal/domain"
+	"strings"
+)
+
+// LDIFSource reads users from LDIF export of the directory,
+// every entry with LoginAttr is a user, StatusAttr is optional.
+type LDIFSource struct {
+	Path       string
+	LoginAttr  string // "uid" usually
+	StatusAttr string // empty - status is not known
+}
+
+func (ls *LDIFSource) Name() string { return ls.Path }
+
+func (ls *LDIFSource) Users(ctx context.Context) ([]domain.DirectoryUser, error) {
+	file, err := os.Open(ls.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer file.Close()
+
+	users := make([]domain.DirectoryUser, 0, 500)
+	user := domain.DirectoryUser{}
+	line := "" // attribute with folded lines
+
+	// addAttr adds the attribute line to the entry
+	addAttr := func() error {
+		if line == "" || strings.HasPrefix(line, "#") {
+			line = ""
+			return nil
+		}
+		name, value, err := parseLDIFLine(line)
+		line = ""
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.EqualFold(name, ls.LoginAttr):
+			user.Login = value
+		case ls.StatusAttr != "" && strings.EqualFold(name, ls.StatusAttr):
+			user.Status = value
+		}
+		return nil
+	}
+	// addUser ends the entry
+	addUser := func() {
+		if user.Login != "" {
+			users = append(users, user)
+		}
+		user = domain.DirectoryUser{}
+	}
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		text := strings.TrimRight(scanner.Text(), "\r")
+
+		// folded line continues the previous one
+		if strings.HasPrefix(text, " ") {
+			line += text[1:]
+			continue
+		}
+		if err := addAttr(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n-1, err)
+		}
+		if text == "" {
+			addUser()
+			continue
+		}
+		line = text
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	if err := addAttr(); err != nil {
+		return nil, fmt.Errorf("last line: %w", err)
+	}
+	addUser()
+
+	return users, nil
+}
+
+// parseLDIFLine parses "name: value" and base64 "name:: value"
+func parseLDIFLine(line string) (string, string, error) {
+	name, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", fmt.Errorf("'%s' is not an attribute", line)
+	}
+	if strings.HasPrefix(value, ":") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+		if err != nil {
+			return "", "", fmt.Errorf("attribute %s: %w", name, err)
+		}
+		return name, string(decoded), nil
+	}
+	return name, strings.TrimSpace(value), nil
+}
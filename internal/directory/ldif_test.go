@@ -0,0 +1,132 @@
+package directory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"session_manager/internal/domain"
+	"testing"
+)
+
+func TestParseLDIFLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{name: "plain", line: "uid: user_1", wantName: "uid", wantValue: "user_1"},
+		{name: "without space", line: "uid:user_1", wantName: "uid", wantValue: "user_1"},
+		{name: "empty value", line: "description:", wantName: "description", wantValue: ""},
+		{name: "colon in value", line: "labeledURI: https://school.example", wantName: "labeledURI", wantValue: "https://school.example"},
+		{name: "base64", line: "cn:: 0JDQudCz0LXRgNC40Lw=", wantName: "cn", wantValue: "Айгерим"},
+		{name: "bad base64", line: "cn:: not base64!", wantErr: true},
+		{name: "not an attribute", line: "user_1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, value, err := parseLDIFLine(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %q: %q", name, value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if name != tt.wantName || value != tt.wantValue {
+				t.Errorf("want %q: %q, got %q: %q", tt.wantName, tt.wantValue, name, value)
+			}
+		})
+	}
+}
+
+func TestLDIFSourceUsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		ldif       string
+		statusAttr string
+		want       []domain.DirectoryUser
+		wantErr    bool
+	}{
+		{
+			name: "entries",
+			ldif: "version: 1\n\n" +
+				"dn: uid=user_1,ou=students,dc=school\nuid: user_1\nemployeeType: active\n\n" +
+				"dn: uid=user_2,ou=students,dc=school\nUID: user_2\n\n" +
+				"dn: ou=students,dc=school\nou: students\n",
+			statusAttr: "employeeType",
+			want: []domain.DirectoryUser{
+				{Login: "user_1", Status: "active"},
+				{Login: "user_2"},
+			},
+		},
+		{
+			name:       "status is not synced",
+			ldif:       "dn: uid=user_1,dc=school\nuid: user_1\nemployeeType: blocked\n",
+			statusAttr: "",
+			want:       []domain.DirectoryUser{{Login: "user_1"}},
+		},
+		{
+			name: "folded lines and comments",
+			ldif: "# export of 2023-09-01\n" +
+				"dn: uid=user_1,ou=students,\n dc=school\nuid: us\n er_1\n" +
+				"# comment between attributes\n" +
+				"employeeType: grad\n uated\n",
+			statusAttr: "employeeType",
+			want:       []domain.DirectoryUser{{Login: "user_1", Status: "graduated"}},
+		},
+		{
+			name:       "folded base64",
+			ldif:       "dn: uid=user_1,dc=school\nuid:: dXNl\n cl8x\n",
+			statusAttr: "",
+			want:       []domain.DirectoryUser{{Login: "user_1"}},
+		},
+		{
+			name:       "crlf",
+			ldif:       "dn: uid=user_1,dc=school\r\nuid: user_1\r\n\r\ndn: uid=user_2,dc=school\r\nuid: user_2\r\n",
+			statusAttr: "",
+			want:       []domain.DirectoryUser{{Login: "user_1"}, {Login: "user_2"}},
+		},
+		{
+			name:    "broken line",
+			ldif:    "dn: uid=user_1,dc=school\nuser_1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "users.ldif")
+			if err := os.WriteFile(path, []byte(tt.ldif), 0o600); err != nil {
+				t.Fatalf("write file: %s", err)
+			}
+
+			source := NewSource(path, "uid", tt.statusAttr)
+			users, err := source.Users(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %v", users)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(users, tt.want) {
+				t.Errorf("want %v, got %v", tt.want, users)
+			}
+		})
+	}
+}
+
+func TestLDIFSourceNotFound(t *testing.T) {
+	source := NewSource("file://"+filepath.Join(t.TempDir(), "missing.ldif"), "uid", "")
+	if _, err := source.Users(context.Background()); err == nil {
+		t.Fatal("want error for missing file")
+	}
+}
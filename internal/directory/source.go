@@ -0,0 +1,33 @@
+package directory
+
+import (
+	"context"
+	"net/http"
+	"session_manager/internal/domain"
+	"strings"
+	"time"
+)
+
+// UserSource is the external directory of users: school information system, LDAP export etc.
+type UserSource interface {
+	// Name is shown in the sync report
+	Name() string
+	// Users returns all users of the directory, status is empty if not known
+	Users(ctx context.Context) ([]domain.DirectoryUser, error)
+}
+
+// NewSource returns JSON-over-HTTP source for 'http://' and 'https://' location and LDIF file source otherwise,
+// loginAttr and statusAttr are attributes of LDIF entries.
+func NewSource(location, loginAttr, statusAttr string) UserSource {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return &HTTPSource{
+			URL:    location,
+			Client: &http.Client{Timeout: 60 * time.Second},
+		}
+	}
+	return &LDIFSource{
+		Path:       strings.TrimPrefix(location, "file://"),
+		LoginAttr:  loginAttr,
+		StatusAttr: statusAttr,
+	}
+}
@@ -4,6 +4,18 @@ import (
 	"time"
 )
 
+// Clock is the time reported by the computer and the time the request was received by the server
+type Clock struct {
+	ClientDateTime time.Time
+	ServerDateTime time.Time
+	Skewed         bool // skew exceeded the allowed threshold
+}
+
+// Skew is how far the computer clock is ahead of the server (negative if behind)
+func (c *Clock) Skew() time.Duration {
+	return c.ClientDateTime.Sub(c.ServerDateTime)
+}
+
 type Session struct {
 	ID            string
 	ComputerName  string
@@ -12,20 +24,213 @@ type Session struct {
 	NextPing      time.Duration
 	StartDateTime time.Time
 	EndDateTime   time.Time
+	Clock
 }
 
 type Activity struct {
 	SessionID     string
 	SessionType   string
 	Login         string
+	IPAddress     string
 	StartDateTime time.Time
 	EndDateTime   time.Time
+	AckCommands   []int64
+	Idle          time.Duration // no keyboard/mouse activity since previous ping
+	InputEvents   int
+	Clock
+}
+
+type SessionEnd struct {
+	ID          string
+	Reason      string
+	EndDateTime time.Time
+}
+
+type SessionTerminate struct {
+	SessionID   string
+	Login       string
+	Admin       string
+	Comment     string
+	Reason      string
+	EndDateTime time.Time
+}
+
+type Command struct {
+	ComputerName string
+	SessionID    string
+	Command      string
+	Message      string
+	NextPing     time.Duration
+	Admin        string
+}
+
+type CommandFilter struct {
+	ComputerName string
+	SessionID    string
+	PendingOnly  bool
+}
+
+// DefaultPolicyStatus is the policy for users without status or without policy for their status
+const DefaultPolicyStatus = "default"
+
+type SessionPolicy struct {
+	Status          string
+	MaxSessions     int // 0 means unlimited
+	ComputerPattern string
+}
+
+type Zone struct {
+	Name            string
+	ComputerPattern string
+	Priority        int
+}
+
+type Cohort struct {
+	Name        string
+	Description string
+}
+
+type CohortMembers struct {
+	Cohort string
+	Logins []string
+}
+
+type CohortActivity struct {
+	Cohort string
+	UserActivity
+}
+
+type ClockSkewFilter struct {
+	MinSkew time.Duration
+}
+
+// types of heartbeats in the log
+const (
+	HeartbeatSession  = "session"
+	HeartbeatActivity = "activity"
+	HeartbeatResume   = "resume"
+	HeartbeatEnd      = "end"
+)
+
+// Heartbeat is an entry of the append-only log of notifications from computers
+type Heartbeat struct {
+	SessionID      string
+	Type           string
+	SessionType    string
+	Login          string
+	ComputerName   string
+	IPAddress      string
+	NextPing       time.Duration
+	DateTime       time.Time // accepted time (after clock policy)
+	ClientDateTime time.Time
+	ServerDateTime time.Time
+	Idle           time.Duration
+	InputEvents    int
+	EndReason      string
+}
+
+type Rebuild struct {
+	FromDate time.Time
+	ToDate   time.Time
+	Admin    string
+}
+
+type UserFilter struct {
+	Prefix  string
+	Status  string
+	Deleted bool
+	Limit   int
+	Offset  int
+}
+
+type UserUpdate struct {
+	Login  string
+	Status string
+}
+
+// Import is the csv import, Columns are header names by column
+type Import struct {
+	DryRun  bool
+	Columns map[string]string
+}
+
+type UserImport struct {
+	Line    int
+	Login   string
+	Status  string // empty - not changed
+	Cohort  string // empty - not changed
+	Invalid string // the reason if the row is invalid
+}
+
+type ComputerImport struct {
+	Line int
+	ComputerUpdate
+	Invalid string // the reason if the row is invalid
+}
+
+// DirectoryUser is the user of the external directory, Status is empty if not known
+type DirectoryUser struct {
+	Login  string
+	Status string
+}
+
+type UserSync struct {
+	Source    string
+	StartedAt time.Time
+	Users     []DirectoryUser
+	Invalid   int // skipped users of the directory
+}
+
+type Leaderboard struct {
+	SessionType string
+	FromDate    time.Time
+	ToDate      time.Time
+	Cohort      string // empty - all users
+	Status      string // empty - any status
+	ActiveOnly  bool   // rank by not idle time
+	Ascending   bool
+	Limit       int
+	Offset      int
+	Location    *time.Location // timezone of the days, nil - campus timezone
+}
+
+type ComputerFilter struct {
+	Prefix string
+	Status string
+	Limit  int
+	Offset int
+}
+
+// Location of the computer, nil fields are not changed on update
+type Location struct {
+	Building *string
+	Floor    *string
+	Zone     *string
+	Row      *string
+	Seat     *string
+	X        *float64
+	Y        *float64
+}
+
+type ComputerUpdate struct {
+	Name   string
+	Status *string // nil - not changed
+	Location
+	ClearPosition bool // x and y are reset to null
+}
+
+type FloorFilter struct {
+	Building string
+	Floor    string
 }
 
 type UserActivity struct {
 	SessionType string
 	Login       string
+	Logins      []string // members of the cohort instead of the single login
 	FromDate    time.Time
 	ToDate      time.Time
 	GroupBy     string
+	ActiveOnly  bool           // count only not idle time
+	Location    *time.Location // timezone of the days and buckets, nil - campus timezone
 }
@@ -0,0 +1,198 @@
+package request
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"session_manager/internal/domain"
+	"strconv"
+	"strings"
+)
+
+// columns of csv import, header of the file is matched case-insensitive,
+// other names can be mapped by the 'columns' query param
+var (
+	UserColumns     = []string{"login", "status", "cohort"}
+	ComputerColumns = []string{"name", "status", "building", "floor", "zone", "row", "seat", "x", "y"}
+)
+
+type Import struct {
+	DryRun  bool   `query:"dry_run"`
+	Columns string `query:"columns"` // "login:Student ID,cohort:Intake"
+}
+
+// Validate returns header names of the known columns
+func (i *Import) Validate(columns []string) (*domain.Import, error) {
+	dto := domain.Import{
+		DryRun:  i.DryRun,
+		Columns: make(map[string]string, len(columns)),
+	}
+	for _, column := range columns {
+		dto.Columns[column] = column
+	}
+
+	if i.Columns == "" {
+		return &dto, nil
+	}
+
+	for _, pair := range strings.Split(i.Columns, ",") {
+		column, header, ok := strings.Cut(pair, ":")
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !ok || header == "" {
+			return nil, fmt.Errorf("columns: '%s' must be 'column:header'", pair)
+		}
+		if _, ok := dto.Columns[column]; !ok {
+			return nil, fmt.Errorf("columns: unknown column '%s', must be one of '%s'",
+				column, strings.Join(columns, "', '"))
+		}
+		dto.Columns[column] = strings.TrimSpace(header)
+	}
+
+	return &dto, nil
+}
+
+// CSVReader reads the file row by row, so big files are not loaded in memory
+type CSVReader struct {
+	reader *csv.Reader
+	index  map[string]int // column - index in the row
+}
+
+// NewCSVReader reads the header, the first of columns is required.
+func NewCSVReader(r io.Reader, dto *domain.Import, columns []string) (*CSVReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // checked by index
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv is empty")
+		}
+		return nil, fmt.Errorf("csv header: %w", err)
+	}
+
+	cr := CSVReader{
+		reader: reader,
+		index:  make(map[string]int, len(columns)),
+	}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")) // excel adds BOM
+		for _, column := range columns {
+			if strings.EqualFold(name, dto.Columns[column]) {
+				cr.index[column] = i
+			}
+		}
+	}
+
+	if _, ok := cr.index[columns[0]]; !ok {
+		return nil, fmt.Errorf("csv header: column '%s' not found", dto.Columns[columns[0]])
+	}
+
+	return &cr, nil
+}
+
+// next returns the line number and cells of the row by column, io.EOF at the end
+func (cr *CSVReader) next() (int, map[string]string, error) {
+	record, err := cr.reader.Read()
+	if err != nil {
+		return 0, nil, err
+	}
+	line, _ := cr.reader.FieldPos(0)
+
+	cells := make(map[string]string, len(cr.index))
+	for column, i := range cr.index {
+		if i < len(record) {
+			cells[column] = strings.TrimSpace(record[i])
+		}
+	}
+
+	return line, cells, nil
+}
+
+// NextUser returns the next user, invalid users have the reason in Invalid, io.EOF at the end
+func (cr *CSVReader) NextUser() (*domain.UserImport, error) {
+	line, cells, err := cr.next()
+	if err != nil {
+		return nil, err
+	}
+
+	user := domain.UserImport{
+		Line:   line,
+		Login:  cells["login"],
+		Status: cells["status"],
+		Cohort: cells["cohort"],
+	}
+
+	if err := validName(user.Login, 50); err != nil {
+		user.Invalid = err.Error()
+	} else if user.Status != "" && validUserStatus(user.Status) != nil {
+		user.Invalid = validUserStatus(user.Status).Error()
+	} else if user.Cohort != "" && validCohortName(user.Cohort) != nil {
+		user.Invalid = validCohortName(user.Cohort).Error()
+	}
+
+	return &user, nil
+}
+
+// NextComputer returns the next computer, empty cells are not changed,
+// invalid computers have the reason in Invalid, io.EOF at the end
+func (cr *CSVReader) NextComputer() (*domain.ComputerImport, error) {
+	line, cells, err := cr.next()
+	if err != nil {
+		return nil, err
+	}
+
+	computer := domain.ComputerImport{Line: line}
+
+	req := ComputerUpdate{
+		Name:     cells["name"],
+		Status:   cellPtr(cells["status"]),
+		Building: cellPtr(cells["building"]),
+		Floor:    cellPtr(cells["floor"]),
+		Zone:     cellPtr(cells["zone"]),
+		Row:      cellPtr(cells["row"]),
+		Seat:     cellPtr(cells["seat"]),
+	}
+	computer.Name = req.Name
+
+	if err := validName(req.Name, 30); err != nil {
+		computer.Invalid = err.Error()
+		return &computer, nil
+	}
+	if req.X, err = cellFloat(cells["x"]); err != nil {
+		computer.Invalid = fmt.Sprintf("x: %s", err)
+		return &computer, nil
+	}
+	if req.Y, err = cellFloat(cells["y"]); err != nil {
+		computer.Invalid = fmt.Sprintf("y: %s", err)
+		return &computer, nil
+	}
+
+	dto, err := req.Validate()
+	if err != nil {
+		computer.Invalid = err.Error()
+		return &computer, nil
+	}
+	computer.ComputerUpdate = *dto
+
+	return &computer, nil
+}
+
+func cellPtr(cell string) *string {
+	if cell == "" {
+		return nil
+	}
+	return &cell
+}
+
+func cellFloat(cell string) (*float64, error) {
+	if cell == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(cell, 64)
+	if err != nil {
+		return nil, errors.New("must be a number")
+	}
+	return &f, nil
+}
@@ -0,0 +1,182 @@
+package request
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestImportValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "default headers",
+			columns: "",
+			want:    map[string]string{"login": "login", "status": "status", "cohort": "cohort"},
+		},
+		{
+			name:    "mapped headers",
+			columns: " Login:Student ID,cohort: Intake ",
+			want:    map[string]string{"login": "Student ID", "status": "status", "cohort": "Intake"},
+		},
+		{
+			name:    "without header",
+			columns: "login:",
+			wantErr: true,
+		},
+		{
+			name:    "unknown column",
+			columns: "email:E-mail",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto, err := (&Import{Columns: tt.columns}).Validate(UserColumns)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %v", dto.Columns)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			for column, header := range tt.want {
+				if dto.Columns[column] != header {
+					t.Errorf("column %s: want header %q, got %q", column, header, dto.Columns[column])
+				}
+			}
+		})
+	}
+}
+
+func TestNewCSVReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		wantErr bool
+	}{
+		{name: "header only", csv: "login,status\n"},
+		{name: "header with BOM and case", csv: "\ufeff LOGIN ,Status\nuser_1,active\n"},
+		{name: "empty", csv: "", wantErr: true},
+		{name: "required column is missing", csv: "status,cohort\nactive,2023-09\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto, _ := (&Import{}).Validate(UserColumns)
+			_, err := NewCSVReader(strings.NewReader(tt.csv), dto, UserColumns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCSVReaderNextUser(t *testing.T) {
+	const file = `Student ID,status,cohort
+user_1,active,2023-09
+user_2,,
+,active,
+user_4,unknown,
+"user_5",blocked
+`
+	dto, err := (&Import{Columns: "login:Student ID"}).Validate(UserColumns)
+	if err != nil {
+		t.Fatalf("validate: %s", err)
+	}
+	reader, err := NewCSVReader(strings.NewReader(file), dto, UserColumns)
+	if err != nil {
+		t.Fatalf("new reader: %s", err)
+	}
+
+	tests := []struct {
+		line    int
+		login   string
+		status  string
+		cohort  string
+		invalid bool
+	}{
+		{line: 2, login: "user_1", status: "active", cohort: "2023-09"},
+		{line: 3, login: "user_2"},
+		{line: 4, status: "active", invalid: true},
+		{line: 5, login: "user_4", status: "unknown", invalid: true},
+		{line: 6, login: "user_5", status: "blocked"}, // short row
+	}
+
+	for _, tt := range tests {
+		user, err := reader.NextUser()
+		if err != nil {
+			t.Fatalf("line %d: unexpected error: %s", tt.line, err)
+		}
+		if user.Line != tt.line || user.Login != tt.login || user.Status != tt.status || user.Cohort != tt.cohort {
+			t.Errorf("line %d: want %+v, got %+v", tt.line, tt, *user)
+		}
+		if (user.Invalid != "") != tt.invalid {
+			t.Errorf("line %d: want invalid %v, got %q", tt.line, tt.invalid, user.Invalid)
+		}
+	}
+
+	if _, err := reader.NextUser(); !errors.Is(err, io.EOF) {
+		t.Fatalf("want io.EOF at the end, got %v", err)
+	}
+}
+
+func TestCSVReaderNextComputer(t *testing.T) {
+	const file = `name,status,floor,x,y
+academie-mac-pink0001,maintenance,2,12.5,4
+academie-mac-pink0002,,,,
+academie-mac-pink0003,,,left,
+academie-mac-pink0004,broken,,,
+`
+	dto, _ := (&Import{}).Validate(ComputerColumns)
+	reader, err := NewCSVReader(strings.NewReader(file), dto, ComputerColumns)
+	if err != nil {
+		t.Fatalf("new reader: %s", err)
+	}
+
+	computer, err := reader.NextComputer()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if computer.Invalid != "" {
+		t.Fatalf("line 2: unexpected invalid: %s", computer.Invalid)
+	}
+	if computer.Name != "academie-mac-pink0001" || *computer.Status != "maintenance" || *computer.Floor != "2" ||
+		*computer.X != 12.5 || *computer.Y != 4 {
+		t.Errorf("line 2: got %+v", computer.ComputerUpdate)
+	}
+	if computer.Building != nil {
+		t.Errorf("line 2: building is not in the file, got %q", *computer.Building)
+	}
+
+	// empty cells are not changed
+	computer, err = reader.NextComputer()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if computer.Invalid != "" || computer.Status != nil || computer.Floor != nil || computer.X != nil {
+		t.Errorf("line 3: want only name, got %+v, invalid %q", computer.ComputerUpdate, computer.Invalid)
+	}
+
+	for _, line := range []int{4, 5} {
+		computer, err = reader.NextComputer()
+		if err != nil {
+			t.Fatalf("line %d: unexpected error: %s", line, err)
+		}
+		if computer.Line != line || computer.Invalid == "" {
+			t.Errorf("line %d: want invalid, got line %d, invalid %q", line, computer.Line, computer.Invalid)
+		}
+	}
+
+	if _, err := reader.NextComputer(); !errors.Is(err, io.EOF) {
+		t.Fatalf("want io.EOF at the end, got %v", err)
+	}
+}
@@ -2,18 +2,236 @@ package request
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"session_manager/internal/domain"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type User struct {
 	Name string `json:"name"`
 }
 
+func (u *User) Validate() error {
+	return validName(u.Name, 50)
+}
+
+const (
+	UserStatusActive    = "active"
+	UserStatusBlocked   = "blocked"
+	UserStatusExpelled  = "expelled"
+	UserStatusGraduated = "graduated"
+	UserStatusStaff     = "staff"
+	UserStatusMentor    = "mentor"
+)
+
+// IsUserStatus reports if the status is known
+func IsUserStatus(status string) bool {
+	return validUserStatus(status) == nil
+}
+
+func validUserStatus(status string) error {
+	switch status {
+	case UserStatusActive, UserStatusBlocked, UserStatusExpelled, UserStatusGraduated, UserStatusStaff, UserStatusMentor:
+		return nil
+	}
+	return errors.New("status must be 'active', 'blocked', 'expelled', 'graduated', 'staff' or 'mentor'")
+}
+
+type UserFilter struct {
+	Prefix  string `query:"prefix"`
+	Status  string `query:"status"`
+	Deleted bool   `query:"deleted"` // show soft deleted users
+	Limit   int    `query:"limit"`
+	Offset  int    `query:"offset"`
+}
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+func (uf *UserFilter) Validate() (*domain.UserFilter, error) {
+	if uf.Status != "" {
+		if err := validUserStatus(uf.Status); err != nil {
+			return nil, err
+		}
+	}
+	if uf.Limit < 0 || uf.Limit > maxLimit {
+		return nil, fmt.Errorf("limit must be from 0 to %d", maxLimit)
+	}
+	if uf.Limit == 0 {
+		uf.Limit = defaultLimit
+	}
+	if uf.Offset < 0 {
+		return nil, errors.New("offset less than 0")
+	}
+	return &domain.UserFilter{
+		Prefix:  uf.Prefix,
+		Status:  uf.Status,
+		Deleted: uf.Deleted,
+		Limit:   uf.Limit,
+		Offset:  uf.Offset,
+	}, nil
+}
+
+type UserUpdate struct {
+	Login  string `param:"login" json:"-"`
+	Status string `json:"status"`
+}
+
+func (uu *UserUpdate) Validate() (*domain.UserUpdate, error) {
+	if uu.Login == "" {
+		return nil, errors.New("login is empty")
+	}
+	if err := validUserStatus(uu.Status); err != nil {
+		return nil, err
+	}
+	return &domain.UserUpdate{
+		Login:  uu.Login,
+		Status: uu.Status,
+	}, nil
+}
+
 type Computer struct {
 	Name string `json:"name"`
 }
 
+func (c *Computer) Validate() error {
+	return validName(c.Name, 30)
+}
+
+// validName checks the name against the limit of characters of the column
+func validName(name string, max int) error {
+	if name == "" {
+		return errors.New("name is empty")
+	}
+	if strings.TrimSpace(name) != name {
+		return errors.New("name has leading or trailing spaces")
+	}
+	if utf8.RuneCountInString(name) > max {
+		return fmt.Errorf("name is longer than %d characters", max)
+	}
+	return nil
+}
+
+const (
+	ComputerStatusActive      = "active"
+	ComputerStatusMaintenance = "maintenance"
+	ComputerStatusRetired     = "retired"
+	ComputerStatusUnverified  = "unverified" // registered by the first session
+)
+
+func validComputerStatus(status string) error {
+	switch status {
+	case ComputerStatusActive, ComputerStatusMaintenance, ComputerStatusRetired, ComputerStatusUnverified:
+		return nil
+	}
+	return errors.New("status must be 'active', 'maintenance', 'retired' or 'unverified'")
+}
+
+type ComputerFilter struct {
+	Prefix string `query:"prefix"`
+	Status string `query:"status"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+func (cf *ComputerFilter) Validate() (*domain.ComputerFilter, error) {
+	if cf.Status != "" {
+		if err := validComputerStatus(cf.Status); err != nil {
+			return nil, err
+		}
+	}
+	if cf.Limit < 0 || cf.Limit > maxLimit {
+		return nil, fmt.Errorf("limit must be from 0 to %d", maxLimit)
+	}
+	if cf.Limit == 0 {
+		cf.Limit = defaultLimit
+	}
+	if cf.Offset < 0 {
+		return nil, errors.New("offset less than 0")
+	}
+	return &domain.ComputerFilter{
+		Prefix: cf.Prefix,
+		Status: cf.Status,
+		Limit:  cf.Limit,
+		Offset: cf.Offset,
+	}, nil
+}
+
+// ComputerUpdate changes only the sent fields, empty string clears location field
+type ComputerUpdate struct {
+	Name     string   `param:"name" json:"-"`
+	Status   *string  `json:"status"`
+	Building *string  `json:"building"`
+	Floor    *string  `json:"floor"`
+	Zone     *string  `json:"zone"`
+	Row      *string  `json:"row"`
+	Seat     *string  `json:"seat"`
+	X        *float64 `json:"x"`
+	Y        *float64 `json:"y"`
+
+	ClearPosition bool `json:"clear_position"` // resets x and y
+}
+
+func (cu *ComputerUpdate) Validate() (*domain.ComputerUpdate, error) {
+	if cu.Name == "" {
+		return nil, errors.New("name is empty")
+	}
+	if cu.Status != nil {
+		if err := validComputerStatus(*cu.Status); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range []struct {
+		name  string
+		value *string
+		max   int
+	}{
+		{"building", cu.Building, 30},
+		{"floor", cu.Floor, 10},
+		{"zone", cu.Zone, 30},
+		{"row", cu.Row, 10},
+		{"seat", cu.Seat, 10},
+	} {
+		if field.value != nil && len(*field.value) > field.max {
+			return nil, fmt.Errorf("%s is longer than %d characters", field.name, field.max)
+		}
+	}
+	if cu.ClearPosition && (cu.X != nil || cu.Y != nil) {
+		return nil, errors.New("clear_position with x or y")
+	}
+	return &domain.ComputerUpdate{
+		Name:          cu.Name,
+		Status:        cu.Status,
+		ClearPosition: cu.ClearPosition,
+		Location: domain.Location{
+			Building: cu.Building,
+			Floor:    cu.Floor,
+			Zone:     cu.Zone,
+			Row:      cu.Row,
+			Seat:     cu.Seat,
+			X:        cu.X,
+			Y:        cu.Y,
+		},
+	}, nil
+}
+
+type FloorFilter struct {
+	Building string `query:"building"`
+	Floor    string `query:"floor"`
+}
+
+func (ff *FloorFilter) Validate() (*domain.FloorFilter, error) {
+	return &domain.FloorFilter{
+		Building: ff.Building,
+		Floor:    ff.Floor,
+	}, nil
+}
+
 type Session struct {
 	ID              string `json:"id"`
 	ComputerName    string `json:"comp_name"`
@@ -23,7 +241,7 @@ type Session struct {
 	DateTime        string `json:"date_time"`
 }
 
-func (s *Session) Validate() (*domain.Session, error) {
+func (s *Session) Validate(loc *time.Location) (*domain.Session, error) {
 	if s.ID == "" {
 		return nil, errors.New("id is empty")
 	}
@@ -43,28 +261,33 @@ func (s *Session) Validate() (*domain.Session, error) {
 		Login:        s.Login,
 		NextPing:     time.Duration(s.NextPingSeconds) * time.Second,
 	}
+	dto.ServerDateTime = time.Now()
 	if s.DateTime == "" {
-		dto.StartDateTime = time.Now()
+		dto.StartDateTime = dto.ServerDateTime
 	} else {
-		t, err := parseDate(s.DateTime)
+		t, err := parseDate(s.DateTime, loc)
 		if err != nil {
 			return nil, err
 		}
 		dto.StartDateTime = t
 	}
+	dto.ClientDateTime = dto.StartDateTime
 	dto.EndDateTime = dto.StartDateTime.Add(dto.NextPing)
 	return &dto, nil
 }
 
 type Activity struct {
-	SessionID       string `json:"session_id"`
-	SessionType     string `json:"session_type,omitempty"`
-	Login           string `json:"login"`
-	NextPingSeconds int    `json:"next_ping_sec"`
-	DateTime        string `json:"date_time"`
+	SessionID       string  `json:"session_id"`
+	SessionType     string  `json:"session_type,omitempty"`
+	Login           string  `json:"login"`
+	NextPingSeconds int     `json:"next_ping_sec"`
+	DateTime        string  `json:"date_time"`
+	AckCommands     []int64 `json:"ack_commands,omitempty"` // ids of executed commands
+	IdleSeconds     int     `json:"idle_sec"`               // no keyboard/mouse activity since previous ping
+	InputEvents     int     `json:"input_events"`           // keyboard/mouse events since previous ping
 }
 
-func (a *Activity) Validate() (*domain.Activity, error) {
+func (a *Activity) Validate(loc *time.Location) (*domain.Activity, error) {
 	if a.SessionID == "" {
 		return nil, errors.New("session_id is empty")
 	}
@@ -74,89 +297,523 @@ func (a *Activity) Validate() (*domain.Activity, error) {
 	if a.NextPingSeconds <= 0 {
 		return nil, errors.New("next ping duration less or eq 0")
 	}
+	if a.IdleSeconds < 0 {
+		return nil, errors.New("idle_sec less than 0")
+	}
+	if a.InputEvents < 0 {
+		return nil, errors.New("input_events less than 0")
+	}
 	dto := domain.Activity{
 		SessionID:   a.SessionID,
 		SessionType: a.SessionType,
 		Login:       a.Login,
+		AckCommands: a.AckCommands,
+		Idle:        time.Duration(a.IdleSeconds) * time.Second,
+		InputEvents: a.InputEvents,
 	}
+	dto.ServerDateTime = time.Now()
 	if a.DateTime == "" {
-		dto.StartDateTime = time.Now()
+		dto.StartDateTime = dto.ServerDateTime
 	} else {
-		t, err := parseDate(a.DateTime)
+		t, err := parseDate(a.DateTime, loc)
 		if err != nil {
 			return nil, err
 		}
 		dto.StartDateTime = t
 	}
+	dto.ClientDateTime = dto.StartDateTime
 	dto.EndDateTime = dto.StartDateTime.Add(time.Duration(a.NextPingSeconds) * time.Second)
 	return &dto, nil
 }
 
+type SessionEnd struct {
+	ID       string `param:"id" json:"-"`
+	Reason   string `json:"reason"`
+	DateTime string `json:"date_time"`
+}
+
+const (
+	EndReasonLogout   = "logout"
+	EndReasonShutdown = "shutdown"
+	EndReasonTimeout  = "timeout"
+	EndReasonForced   = "forced"
+)
+
+func (se *SessionEnd) Validate(loc *time.Location) (*domain.SessionEnd, error) {
+	if se.ID == "" {
+		return nil, errors.New("id is empty")
+	}
+	if se.Reason == "" {
+		se.Reason = EndReasonLogout
+	}
+	switch se.Reason {
+	case EndReasonLogout, EndReasonShutdown, EndReasonTimeout, EndReasonForced:
+	default:
+		return nil, errors.New("reason must be 'logout', 'shutdown', 'timeout' or 'forced'")
+	}
+	dto := domain.SessionEnd{
+		ID:     se.ID,
+		Reason: se.Reason,
+	}
+	if se.DateTime == "" {
+		dto.EndDateTime = time.Now()
+	} else {
+		t, err := parseDate(se.DateTime, loc)
+		if err != nil {
+			return nil, err
+		}
+		dto.EndDateTime = t
+	}
+	return &dto, nil
+}
+
+type SessionTerminate struct {
+	SessionID string `json:"session_id"`
+	Login     string `json:"login"`
+	Admin     string `json:"admin"`
+	Comment   string `json:"comment"`
+}
+
+func (st *SessionTerminate) Validate() (*domain.SessionTerminate, error) {
+	if st.SessionID == "" && st.Login == "" {
+		return nil, errors.New("session_id or login is required")
+	}
+	if st.Admin == "" {
+		return nil, errors.New("admin is empty")
+	}
+	return &domain.SessionTerminate{
+		SessionID:   st.SessionID,
+		Login:       st.Login,
+		Admin:       st.Admin,
+		Comment:     st.Comment,
+		Reason:      EndReasonForced,
+		EndDateTime: time.Now(),
+	}, nil
+}
+
+type Command struct {
+	ComputerName    string `json:"comp_name"`
+	SessionID       string `json:"session_id"`
+	Command         string `json:"command"`
+	Message         string `json:"message"`
+	NextPingSeconds int    `json:"next_ping_sec"`
+	Admin           string `json:"admin"`
+}
+
+const (
+	CommandLogout          = "logout"
+	CommandLockScreen      = "lock_screen"
+	CommandShowMessage     = "show_message"
+	CommandSetPingInterval = "set_ping_interval"
+)
+
+func (c *Command) Validate() (*domain.Command, error) {
+	if c.ComputerName == "" && c.SessionID == "" {
+		return nil, errors.New("comp_name or session_id is required")
+	}
+	if c.Admin == "" {
+		return nil, errors.New("admin is empty")
+	}
+	switch c.Command {
+	case CommandLogout, CommandLockScreen:
+	case CommandShowMessage:
+		if c.Message == "" {
+			return nil, errors.New("message is empty")
+		}
+	case CommandSetPingInterval:
+		if c.NextPingSeconds <= 0 {
+			return nil, errors.New("next ping duration less or eq 0")
+		}
+	default:
+		return nil, errors.New("command must be 'logout', 'lock_screen', 'show_message' or 'set_ping_interval'")
+	}
+	return &domain.Command{
+		ComputerName: c.ComputerName,
+		SessionID:    c.SessionID,
+		Command:      c.Command,
+		Message:      c.Message,
+		NextPing:     time.Duration(c.NextPingSeconds) * time.Second,
+		Admin:        c.Admin,
+	}, nil
+}
+
+type CommandFilter struct {
+	ComputerName string `query:"comp_name"`
+	SessionID    string `query:"session_id"`
+	Pending      bool   `query:"pending"`
+}
+
+func (cf *CommandFilter) Validate() (*domain.CommandFilter, error) {
+	return &domain.CommandFilter{
+		ComputerName: cf.ComputerName,
+		SessionID:    cf.SessionID,
+		PendingOnly:  cf.Pending,
+	}, nil
+}
+
+type SessionPolicy struct {
+	Status          string `param:"status" json:"-"`
+	MaxSessions     int    `json:"max_sessions"`
+	ComputerPattern string `json:"comp_pattern"`
+}
+
+func (sp *SessionPolicy) Validate() (*domain.SessionPolicy, error) {
+	if sp.Status == "" {
+		return nil, errors.New("status is empty")
+	}
+	if len(sp.Status) > 15 {
+		return nil, errors.New("status is longer than 15 characters")
+	}
+	if sp.MaxSessions < 0 {
+		return nil, errors.New("max_sessions less than 0")
+	}
+	if len(sp.ComputerPattern) > 100 {
+		return nil, errors.New("comp_pattern is longer than 100 characters")
+	}
+	if _, err := CompileNamePattern(sp.ComputerPattern); err != nil {
+		return nil, fmt.Errorf("comp_pattern: %w", err)
+	}
+	return &domain.SessionPolicy{
+		Status:          sp.Status,
+		MaxSessions:     sp.MaxSessions,
+		ComputerPattern: sp.ComputerPattern,
+	}, nil
+}
+
+// CompileNamePattern compiles the regexp of computer names, it matches the whole name
+func CompileNamePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+type Zone struct {
+	Name            string `param:"name" json:"-"`
+	ComputerPattern string `json:"comp_pattern"`
+	Priority        int    `json:"priority"`
+}
+
+func (z *Zone) Validate() (*domain.Zone, error) {
+	if z.Name == "" {
+		return nil, errors.New("name is empty")
+	}
+	if len(z.Name) > 30 {
+		return nil, errors.New("name is longer than 30 characters")
+	}
+	if len(z.ComputerPattern) > 100 {
+		return nil, errors.New("comp_pattern is longer than 100 characters")
+	}
+	if _, err := CompileNamePattern(z.ComputerPattern); err != nil {
+		return nil, fmt.Errorf("comp_pattern: %w", err)
+	}
+	return &domain.Zone{
+		Name:            z.Name,
+		ComputerPattern: z.ComputerPattern,
+		Priority:        z.Priority,
+	}, nil
+}
+
+type Cohort struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (co *Cohort) Validate() (*domain.Cohort, error) {
+	if err := validCohortName(co.Name); err != nil {
+		return nil, err
+	}
+	if len(co.Description) > 100 {
+		return nil, errors.New("description is longer than 100 characters")
+	}
+	return &domain.Cohort{
+		Name:        co.Name,
+		Description: co.Description,
+	}, nil
+}
+
+func validCohortName(name string) error {
+	if name == "" {
+		return errors.New("cohort name is empty")
+	}
+	if len(name) > 30 {
+		return errors.New("cohort name is longer than 30 characters")
+	}
+	return nil
+}
+
+type CohortMembers struct {
+	Cohort string   `param:"name" json:"-"`
+	Logins []string `json:"logins"`
+}
+
+func (cm *CohortMembers) Validate() (*domain.CohortMembers, error) {
+	if err := validCohortName(cm.Cohort); err != nil {
+		return nil, err
+	}
+	if len(cm.Logins) == 0 {
+		return nil, errors.New("logins is empty")
+	}
+	for _, login := range cm.Logins {
+		if login == "" {
+			return nil, errors.New("login is empty")
+		}
+	}
+	return &domain.CohortMembers{
+		Cohort: cm.Cohort,
+		Logins: cm.Logins,
+	}, nil
+}
+
+// CohortActivity is the report of all members of the cohort, login param is ignored
+type CohortActivity struct {
+	Cohort string `param:"name"`
+	UserActivity
+}
+
+func (ca *CohortActivity) Validate() (*domain.CohortActivity, error) {
+	if err := validCohortName(ca.Cohort); err != nil {
+		return nil, err
+	}
+	dto, err := ca.UserActivity.validate()
+	if err != nil {
+		return nil, err
+	}
+	dto.Login = ""
+	return &domain.CohortActivity{
+		Cohort:       ca.Cohort,
+		UserActivity: *dto,
+	}, nil
+}
+
+type ClockSkewFilter struct {
+	MinSeconds int `query:"min_sec"`
+}
+
+func (cs *ClockSkewFilter) Validate() (*domain.ClockSkewFilter, error) {
+	if cs.MinSeconds < 0 {
+		return nil, errors.New("min_sec less than 0")
+	}
+	return &domain.ClockSkewFilter{
+		MinSkew: time.Duration(cs.MinSeconds) * time.Second,
+	}, nil
+}
+
+type Rebuild struct {
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+	Admin    string `json:"admin"`
+}
+
+func (r *Rebuild) Validate(loc *time.Location) (*domain.Rebuild, error) {
+	if r.Admin == "" {
+		return nil, errors.New("admin is empty")
+	}
+	if r.FromDate == "" {
+		return nil, errors.New("from_date is empty")
+	}
+
+	dto := domain.Rebuild{
+		Admin: r.Admin,
+	}
+
+	t, err := parseDate(r.FromDate, loc)
+	if err != nil {
+		return nil, err
+	}
+	dto.FromDate = day(t)
+
+	if r.ToDate == "" {
+		dto.ToDate = day(time.Now().In(loc))
+	} else {
+		t, err := parseDate(r.ToDate, loc)
+		if err != nil {
+			return nil, err
+		}
+		dto.ToDate = day(t)
+	}
+	if dto.ToDate.Before(dto.FromDate) {
+		return nil, errors.New("to_date is before from_date")
+	}
+
+	return &dto, nil
+}
+
 type UserActivity struct {
 	SessionType string `query:"session_type"` // parsing by link's queries ('omitempty' not working, do not add)
 	Login       string `query:"login"`
 	FromDate    string `query:"from_date"`
 	ToDate      string `query:"to_date"`
 	GroupBy     string `query:"group_by"`
+	ActiveOnly  bool   `query:"active_only"`
+	Timezone    string `query:"tz"`
 }
 
 const (
-	GroupByMonth = "month"
-	GroupByDate  = "date"
+	GroupByMonth   = "month"
+	GroupByDate    = "date"
+	GroupByWeek    = "week"    // ISO week
+	GroupByYear    = "year"    // calendar year
+	GroupByWeekday = "weekday" // Monday..Sunday totals
+	GroupByHour    = "hour"    // hour of the day histogram
 )
 
 func (ua *UserActivity) Validate() (*domain.UserActivity, error) {
 	if ua.Login == "" {
 		return nil, errors.New("login is empty")
 	}
+	return ua.validate()
+}
+
+// validate checks report params common for a user and a cohort
+func (ua *UserActivity) validate() (*domain.UserActivity, error) {
 	if ua.GroupBy == "" {
 		ua.GroupBy = GroupByDate
 	}
-	if ua.GroupBy != GroupByMonth && ua.GroupBy != GroupByDate {
-		return nil, errors.New("group by must be 'month' or 'date'")
+	switch ua.GroupBy {
+	case GroupByMonth, GroupByDate, GroupByWeek, GroupByYear, GroupByWeekday, GroupByHour:
+	default:
+		return nil, errors.New("group by must be 'month', 'date', 'week', 'year', 'weekday' or 'hour'")
 	}
 
 	dto := domain.UserActivity{
 		SessionType: ua.SessionType,
 		Login:       ua.Login,
 		GroupBy:     ua.GroupBy,
+		ActiveOnly:  ua.ActiveOnly,
 	}
 
-	if ua.FromDate == "" && ua.ToDate == "" {
-		dto.FromDate = time.Now().Truncate(24 * time.Hour)
-		dto.ToDate = dto.FromDate.Add(24 * time.Hour)
-		return &dto, nil
+	var err error
+	if dto.Location, err = parseLocation(ua.Timezone); err != nil {
+		return nil, err
+	}
+	if dto.FromDate, dto.ToDate, err = dateRange(ua.FromDate, ua.ToDate); err != nil {
+		return nil, err
+	}
+
+	return &dto, nil
+}
+
+// dateRange returns days of the report as written in the dates (midnight in UTC),
+// empty dates are zero: the service sets them to today of the report's timezone.
+// Only the written day is used, so the timezone of date-times without offset does not matter
+func dateRange(fromDate, toDate string) (from, to time.Time, err error) {
+	if fromDate == "" && toDate == "" {
+		return from, to, nil
 	}
 
-	t, err := parseDate(ua.FromDate)
+	t, err := parseDate(fromDate, time.UTC)
 	if err != nil {
-		return nil, err
+		return from, to, err
 	}
-	dto.FromDate = t.Truncate(24 * time.Hour)
+	from = day(t)
 
-	if ua.ToDate == "" {
-		dto.ToDate = time.Now().Truncate(24 * time.Hour).Add(24 * time.Hour)
-	} else {
-		t, err := parseDate(ua.ToDate)
+	if toDate != "" {
+		t, err := parseDate(toDate, time.UTC)
 		if err != nil {
+			return from, to, err
+		}
+		to = day(t)
+		if to.Before(from) {
+			return from, to, errors.New("to_date is before from_date")
+		}
+	}
+
+	return from, to, nil
+}
+
+// day returns the day of the date as written (midnight in UTC)
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseLocation returns the timezone of the report by IANA name, nil - campus timezone
+func parseLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	// LoadLocation treats "Local" as the server's zone, it is unknown to the database
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, errors.New("tz must be IANA timezone name, e.g. 'Asia/Almaty'")
+	}
+	return loc, nil
+}
+
+type Leaderboard struct {
+	SessionType string `query:"session_type"`
+	FromDate    string `query:"from_date"`
+	ToDate      string `query:"to_date"`
+	Cohort      string `query:"cohort"`
+	Status      string `query:"status"`
+	ActiveOnly  bool   `query:"active_only"`
+	Order       string `query:"order"`
+	Limit       int    `query:"limit"`
+	Offset      int    `query:"offset"`
+	Timezone    string `query:"tz"`
+}
+
+const (
+	OrderDesc = "desc"
+	OrderAsc  = "asc"
+)
+
+func (lb *Leaderboard) Validate() (*domain.Leaderboard, error) {
+	if lb.Cohort != "" {
+		if err := validCohortName(lb.Cohort); err != nil {
 			return nil, err
 		}
-		dto.ToDate = t.Truncate(24 * time.Hour)
+	}
+	if lb.Status != "" {
+		if err := validUserStatus(lb.Status); err != nil {
+			return nil, err
+		}
+	}
+	if lb.Order == "" {
+		lb.Order = OrderDesc
+	}
+	if lb.Order != OrderDesc && lb.Order != OrderAsc {
+		return nil, errors.New("order must be 'desc' or 'asc'")
+	}
+	if lb.Limit < 0 || lb.Limit > maxLimit {
+		return nil, fmt.Errorf("limit must be from 0 to %d", maxLimit)
+	}
+	if lb.Limit == 0 {
+		lb.Limit = defaultLimit
+	}
+	if lb.Offset < 0 {
+		return nil, errors.New("offset less than 0")
+	}
+
+	dto := domain.Leaderboard{
+		SessionType: lb.SessionType,
+		Cohort:      lb.Cohort,
+		Status:      lb.Status,
+		ActiveOnly:  lb.ActiveOnly,
+		Ascending:   lb.Order == OrderAsc,
+		Limit:       lb.Limit,
+		Offset:      lb.Offset,
+	}
+
+	var err error
+	if dto.Location, err = parseLocation(lb.Timezone); err != nil {
+		return nil, err
+	}
+	if dto.FromDate, dto.ToDate, err = dateRange(lb.FromDate, lb.ToDate); err != nil {
+		return nil, err
 	}
 
 	return &dto, nil
 }
 
-func parseDate(s string) (t time.Time, err error) {
+// parseDate parses RFC3339 date-time, date-times without offset are in loc (campus timezone)
+func parseDate(s string, loc *time.Location) (t time.Time, err error) {
 	t, err = time.Parse(time.RFC3339, s)
 	if err == nil {
 		return t, nil
 	}
-	t, err = time.Parse(time.DateTime, s)
+	t, err = time.ParseInLocation(time.DateTime, s, loc)
 	if err == nil {
 		return t, nil
 	}
-	t, err = time.Parse(time.DateOnly, s)
+	t, err = time.ParseInLocation(time.DateOnly, s, loc)
 	if err == nil {
 		return t, nil
 	}
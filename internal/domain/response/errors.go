@@ -1,17 +1,34 @@
 package response
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 
 type ErrBadReq struct{ Message string }
 
 func (e *ErrBadReq) Error() string { return e.Message }
 
+// ErrForbidden denies a session for the user or the computer,
+// Message is human-readable and can be shown on the login screen.
+type ErrForbidden struct {
+	Reason  string
+	Message string
+}
+
+func (e *ErrForbidden) Error() string { return e.Message }
+
 var (
 	ErrAccessDenied = errors.New("access denied")
+	ErrComputerBusy = fmt.Errorf("%w: computer has another active session", ErrAccessDenied)
+	ErrComputerDeny = fmt.Errorf("%w: computer is not allowed for the user", ErrAccessDenied)
 	ErrDuplicateKey = ErrBadReq{"duplicate key error"}
+	ErrForeignKey   = ErrBadReq{"unknown login, computer or session"}
 	ErrNotFound     = ErrBadReq{"not found"}
 	ErrEndStartDate = ErrBadReq{"end_date_time must be greater than start_date_time"}
 	ErrEndEndDate   = ErrBadReq{"end_date_time must be greater than previous value"}
+	ErrSessionEnded = ErrBadReq{"session already ended"}
+	ErrNoSession    = ErrBadReq{"computer has no active session"}
 )
 
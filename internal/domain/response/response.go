@@ -9,6 +9,70 @@ type Data struct {
 	Data    any    `json:"data,omitempty"`
 }
 
+const (
+	ResultCreated   = "created"
+	ResultExists    = "exists"
+	ResultUpdated   = "updated"
+	ResultUnchanged = "unchanged"
+	ResultInvalid   = "invalid"
+)
+
+// CreateResult is the result of creating one item of the bulk request
+type CreateResult struct {
+	Name   string `json:"name"`
+	Result string `json:"result"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type CreateResults struct {
+	Created int            `json:"created"`
+	Exists  int            `json:"exists"`
+	Invalid int            `json:"invalid"`
+	Results []CreateResult `json:"results"`
+}
+
+// ImportResult is the result of one row of csv, Line is the line in the file
+type ImportResult struct {
+	Line   int    `json:"line"`
+	Name   string `json:"name"`
+	Result string `json:"result"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportResults lists only changed and invalid rows, unchanged rows are only counted.
+// Aborted import has results of the committed rows: up to CommittedLine (0 - none), the rest are not imported.
+type ImportResults struct {
+	DryRun        bool           `json:"dry_run"`
+	Aborted       bool           `json:"aborted,omitempty"`
+	CommittedLine int            `json:"committed_line,omitempty"`
+	Created       int            `json:"created"`
+	Updated       int            `json:"updated"`
+	Unchanged     int            `json:"unchanged"`
+	Invalid       int            `json:"invalid"`
+	Results       []ImportResult `json:"results"`
+}
+
+func (ir *ImportResults) Add(result ImportResult) {
+	switch result.Result {
+	case ResultCreated:
+		ir.Created++
+	case ResultUpdated:
+		ir.Updated++
+	case ResultUnchanged:
+		ir.Unchanged++
+		return
+	case ResultInvalid:
+		ir.Invalid++
+	}
+	ir.Results = append(ir.Results, result)
+}
+
+type SessionStart struct {
+	ID      string `json:"id"`
+	Resumed bool   `json:"resumed"`
+}
+
+// Session times are instants in UTC
 type Session struct {
 	ID            string    `db:"id" json:"id"`
 	ComputerName  string    `db:"comp_name" json:"comp_name"`
@@ -18,19 +82,209 @@ type Session struct {
 	EndDateTime   time.Time `db:"end_date_time" json:"end_date_time"`
 }
 
+// Heartbeat is returned to the computer on every activity ping
+type Heartbeat struct {
+	Commands []Command `json:"commands"`
+}
+
+type Command struct {
+	ID              int64  `db:"id" json:"id"`
+	Command         string `db:"command" json:"command"`
+	Message         string `db:"message" json:"message,omitempty"`
+	NextPingSeconds int    `db:"next_ping_sec" json:"next_ping_sec,omitempty"`
+}
+
+type CommandInfo struct {
+	Command
+	ComputerName string     `db:"comp_name" json:"comp_name,omitempty"`
+	SessionID    string     `db:"session_id" json:"session_id,omitempty"`
+	CreatedBy    string     `db:"created_by" json:"created_by"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	DeliveredAt  *time.Time `db:"delivered_at" json:"delivered_at"`
+	AckedAt      *time.Time `db:"acked_at" json:"acked_at"`
+}
+
+type SessionPolicy struct {
+	Status          string `db:"status" json:"status"`
+	MaxSessions     int    `db:"max_sessions" json:"max_sessions"`
+	ComputerPattern string `db:"comp_pattern" json:"comp_pattern"`
+}
+
+type Zone struct {
+	Name            string `db:"name" json:"name"`
+	ComputerPattern string `db:"comp_pattern" json:"comp_pattern"`
+	Priority        int    `db:"priority" json:"priority"`
+}
+
+type ZoneOccupancy struct {
+	Zone        string `json:"zone"`
+	Seats       int    `json:"seats"` // not retired computers
+	Maintenance int    `json:"maintenance"`
+	Online      int    `json:"online"` // sessions
+	Free        int    `json:"free"`
+}
+
+type ComputerClock struct {
+	ComputerName string    `db:"comp_name" json:"comp_name"`
+	SkewSeconds  int       `db:"clock_skew_sec" json:"clock_skew_sec"`
+	CheckedAt    time.Time `db:"clock_checked_at" json:"clock_checked_at"`
+}
+
+type Rebuild struct {
+	Sessions   int `json:"sessions"`
+	Activities int `json:"activities"`
+	Segments   int `json:"segments"`
+}
+
+type User struct {
+	Login     string     `db:"login" json:"login"`
+	Status    string     `db:"status" json:"status"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	RemovedAt *time.Time `db:"removed_at" json:"removed_at,omitempty"` // not found in the user directory
+}
+
+// UserSync is the report of the user directory sync
+type UserSync struct {
+	ID         int64     `db:"id" json:"id"`
+	Source     string    `db:"source" json:"source"`
+	StartedAt  time.Time `db:"started_at" json:"started_at"`
+	FinishedAt time.Time `db:"finished_at" json:"finished_at"`
+	Total      int       `db:"total" json:"total"`
+	Invalid    int       `db:"invalid" json:"invalid"`
+	Created    []string  `db:"created" json:"created"`
+	Updated    []string  `db:"updated" json:"updated"`   // status changed
+	Removed    []string  `db:"removed" json:"removed"`   // not found in the directory
+	Restored   []string  `db:"restored" json:"restored"` // found again
+	Error      string    `db:"error" json:"error,omitempty"`
+}
+
+type Users struct {
+	Total int    `json:"total"`
+	Users []User `json:"users"`
+}
+
+type Computer struct {
+	ComputerName string   `db:"comp_name" json:"comp_name"`
+	Status       string   `db:"status" json:"status"`
+	Building     string   `db:"building" json:"building,omitempty"`
+	Floor        string   `db:"floor" json:"floor,omitempty"`
+	Zone         string   `db:"zone" json:"zone,omitempty"`
+	Row          string   `db:"seat_row" json:"row,omitempty"`
+	Seat         string   `db:"seat" json:"seat,omitempty"`
+	X            *float64 `db:"pos_x" json:"x,omitempty"`
+	Y            *float64 `db:"pos_y" json:"y,omitempty"`
+}
+
+// FloorSeat is a computer on the floor map with its online session if the seat is taken
+type FloorSeat struct {
+	Computer
+	Session *Session `json:"session"`
+}
+
+type FloorMap struct {
+	Total int         `json:"total"`
+	Taken int         `json:"taken"`
+	Seats []FloorSeat `json:"seats"`
+}
+
+type Computers struct {
+	Total     int        `json:"total"`
+	Computers []Computer `json:"computers"`
+}
+
 type UserActivity struct {
-	Login        string  `db:"login" json:"id"`
-	TotalHours   float32 `db:"total_hours" json:"total_hours"`
-	UserActivity any     `json:"user_activity,omitempty"`
+	Login            string  `db:"login" json:"id"`
+	TotalHours       float32 `db:"total_hours" json:"total_hours"`
+	TotalActiveHours float32 `db:"total_active_hours" json:"total_active_hours"`
+	TotalIdleHours   float32 `db:"total_idle_hours" json:"total_idle_hours"`
+	UserActivity     any     `json:"user_activity,omitempty"`
 }
 
-type UserActivityByMonth struct {
-	Year        string  `db:"year" json:"year"`
-	MonthNumber string  `db:"month_number" json:"month_num"`
+type Cohort struct {
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	Members     int       `json:"members"`
+	Logins      []string  `json:"logins,omitempty"`
+}
+
+// CohortMembers lists logins added to (or removed from) the cohort and skipped ones:
+// unknown, deleted or already (not) a member
+type CohortMembers struct {
+	Changed []string `json:"changed"`
+	Skipped []string `json:"skipped"`
+}
+
+// CohortActivity averages are calculated for all members, including members without hours
+type CohortActivity struct {
+	Cohort             string         `json:"cohort"`
+	Members            int            `json:"members"`
+	TotalHours         float32        `json:"total_hours"`
+	TotalActiveHours   float32        `json:"total_active_hours"`
+	TotalIdleHours     float32        `json:"total_idle_hours"`
+	AverageHours       float32        `json:"average_hours"`
+	AverageActiveHours float32        `json:"average_active_hours"`
+	Users              []UserActivity `json:"users"`
+}
+
+// Leaderboard ranks users by hours in the requested order (rank 1 has the least hours for ascending),
+// users with equal hours have the same rank
+type Leaderboard struct {
+	Total int               `json:"total"`
+	Users []LeaderboardUser `json:"users"`
+}
+
+type LeaderboardUser struct {
+	Rank        int     `db:"rank" json:"rank"`
+	Login       string  `db:"login" json:"login"`
+	Status      string  `db:"status" json:"status"`
+	Hours       float32 `db:"hours" json:"hours"`
+	ActiveHours float32 `db:"active_hours" json:"active_hours"`
+	IdleHours   float32 `db:"idle_hours" json:"idle_hours"`
+}
+
+// ActivityHours are hours of the activity report bucket
+type ActivityHours struct {
 	Hours       float32 `db:"hours" json:"hours"`
+	ActiveHours float32 `db:"active_hours" json:"active_hours"`
+	IdleHours   float32 `db:"idle_hours" json:"idle_hours"`
+}
+
+func (ah *ActivityHours) GetHours() *ActivityHours { return ah }
+
+type UserActivityByMonth struct {
+	Year        string `db:"year" json:"year"`
+	MonthNumber string `db:"month_number" json:"month_num"`
+	ActivityHours
 }
 
 type UserActivityByDate struct {
-	Date  time.Time `db:"date" json:"date"`
-	Hours float32   `db:"hours" json:"hours"`
+	Date time.Time `db:"date" json:"date"`
+	ActivityHours
+}
+
+// UserActivityByWeek is ISO week, Year is ISO year of the week
+type UserActivityByWeek struct {
+	Year      int       `db:"iso_year" json:"year"`
+	Week      int       `db:"week" json:"week"`
+	StartDate time.Time `db:"week_start" json:"start_date"` // Monday
+	ActivityHours
+}
+
+type UserActivityByYear struct {
+	Year string `db:"year" json:"year"`
+	ActivityHours
+}
+
+// UserActivityByWeekday is the sum of hours of the day of the week in the range
+type UserActivityByWeekday struct {
+	Weekday int    `db:"weekday" json:"weekday"` // Monday is 1, Sunday is 7
+	Name    string `json:"name"`
+	ActivityHours
+}
+
+// UserActivityByHour is the sum of hours of the hour of the day in the range
+type UserActivityByHour struct {
+	Hour int `db:"hour" json:"hour"` // 0-23
+	ActivityHours
 }
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+	"time"
+)
+
+// SetClockSkew saves the last observed clock skew of the computer,
+// the computer is taken from the session if compName is empty.
+func (s *storage) SetClockSkew(ctx context.Context, compName, sessionID string, skew time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, `UPDATE session.computers
+		SET clock_skew_sec = $1,
+			clock_checked_at = NOW()
+		WHERE comp_name = COALESCE(
+			NULLIF($2, ''),
+			(SELECT comp_name FROM session.in_campus WHERE id = NULLIF($3, '')::uuid)
+		);`,
+		int(skew.Round(time.Second).Seconds()),
+		compName,
+		sessionID,
+	); err != nil {
+		return customErr("exec", err)
+	}
+
+	return nil
+}
+
+func (s *storage) GetClockSkews(ctx context.Context, dto *domain.ClockSkewFilter) ([]response.ComputerClock, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT comp_name, clock_skew_sec, clock_checked_at
+		FROM session.computers
+		WHERE clock_skew_sec IS NOT NULL
+			AND ABS(clock_skew_sec) >= $1
+		ORDER BY ABS(clock_skew_sec) DESC;`,
+		int(dto.MinSkew.Seconds()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	computers := make([]response.ComputerClock, 0, 50)
+
+	for rows.Next() {
+		computer := response.ComputerClock{}
+		if err := rows.Scan(
+			&computer.ComputerName,
+			&computer.SkewSeconds,
+			&computer.CheckedAt,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		computers = append(computers, computer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return computers, nil
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *storage) CreateCohort(ctx context.Context, dto *domain.Cohort) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, `INSERT INTO
+		session.cohorts (name, description)
+		VALUES ($1, NULLIF($2, ''));`,
+		dto.Name,
+		dto.Description,
+	); err != nil {
+		return customErr("exec", err)
+	}
+
+	return nil
+}
+
+func (s *storage) GetCohorts(ctx context.Context) ([]response.Cohort, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT c.name, COALESCE(c.description, ''), c.created_at, COUNT(m.login)
+		FROM session.cohorts c
+		LEFT JOIN session.cohort_members m ON m.cohort = c.name
+		GROUP BY c.name
+		ORDER BY c.name;`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	cohorts := make([]response.Cohort, 0, 20)
+
+	for rows.Next() {
+		cohort := response.Cohort{}
+		if err := rows.Scan(
+			&cohort.Name,
+			&cohort.Description,
+			&cohort.CreatedAt,
+			&cohort.Members,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		cohorts = append(cohorts, cohort)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return cohorts, nil
+}
+
+// GetCohort returns the cohort with logins of its members.
+func (s *storage) GetCohort(ctx context.Context, name string) (*response.Cohort, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cohort := response.Cohort{}
+
+	if err := s.pool.QueryRow(ctx,
+		`SELECT c.name, COALESCE(c.description, ''), c.created_at,
+			COALESCE(ARRAY_AGG(m.login ORDER BY m.login) FILTER (WHERE m.login IS NOT NULL), '{}')
+		FROM session.cohorts c
+		LEFT JOIN session.cohort_members m ON m.cohort = c.name
+		WHERE c.name = $1
+		GROUP BY c.name;`,
+		name,
+	).Scan(
+		&cohort.Name,
+		&cohort.Description,
+		&cohort.CreatedAt,
+		&cohort.Logins,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &response.ErrNotFound
+		}
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+	cohort.Members = len(cohort.Logins)
+
+	return &cohort, nil
+}
+
+func (s *storage) DeleteCohort(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if tag, err := s.pool.Exec(ctx, `DELETE FROM session.cohorts
+		WHERE name = $1;`,
+		name,
+	); err != nil {
+		return customErr("exec", err)
+	} else if tag.RowsAffected() == 0 {
+		return &response.ErrNotFound
+	}
+
+	return nil
+}
+
+// AddCohortMembers adds existing not deleted users to the cohort.
+func (s *storage) AddCohortMembers(ctx context.Context, dto *domain.CohortMembers) (*response.CohortMembers, error) {
+	return s.changeCohortMembers(ctx, dto,
+		`INSERT INTO session.cohort_members (cohort, login)
+		SELECT $1, login
+		FROM public.users
+		WHERE login = ANY($2) AND deleted_at IS NULL
+		ON CONFLICT (cohort, login) DO NOTHING
+		RETURNING login;`,
+	)
+}
+
+func (s *storage) RemoveCohortMembers(ctx context.Context, dto *domain.CohortMembers) (*response.CohortMembers, error) {
+	return s.changeCohortMembers(ctx, dto,
+		`DELETE FROM session.cohort_members
+		WHERE cohort = $1 AND login = ANY($2)
+		RETURNING login;`,
+	)
+}
+
+// changeCohortMembers runs the query returning changed logins, the rest of logins are skipped.
+func (s *storage) changeCohortMembers(ctx context.Context, dto *domain.CohortMembers, query string) (*response.CohortMembers, error) {
+	ctx2, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("changeCohortMembers: rollback: %s", err.Error())
+		}
+	}()
+
+	// lock the cohort so it is not deleted meanwhile
+	if err := tx.QueryRow(ctx2,
+		`SELECT name FROM session.cohorts WHERE name = $1 FOR SHARE;`,
+		dto.Cohort,
+	).Scan(&dto.Cohort); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &response.ErrNotFound
+		}
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	rows, err := tx.Query(ctx2, query, dto.Cohort, dto.Logins)
+	if err != nil {
+		return nil, customErr("query", err)
+	}
+
+	changed := make(map[string]bool, len(dto.Logins))
+	for rows.Next() {
+		var login string
+		if err := rows.Scan(&login); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		changed[login] = true
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	members := response.CohortMembers{
+		Changed: make([]string, 0, len(changed)),
+		Skipped: make([]string, 0, len(dto.Logins)-len(changed)),
+	}
+	for _, login := range dto.Logins {
+		if changed[login] {
+			members.Changed = append(members.Changed, login)
+			delete(changed, login) // duplicates in request
+		} else {
+			members.Skipped = append(members.Skipped, login)
+		}
+	}
+
+	return &members, nil
+}
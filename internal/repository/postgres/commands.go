@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// pendingCommandsFilter matches commands of the session, commands addressed to the computer
+// are bound to its active session when queued, so the next user of the computer does not get them
+const pendingCommandsFilter = `acked_at IS NULL
+	AND session_id = $1`
+
+func (s *storage) CreateCommand(ctx context.Context, dto *domain.Command) (int64, error) {
+	ctx2, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("CreateCommand: rollback: %s", err.Error())
+		}
+	}()
+
+	sessionID := dto.SessionID
+	if sessionID == "" {
+		if err := tx.QueryRow(ctx2, fmt.Sprintf(`SELECT id::text
+			FROM session.in_campus
+			WHERE comp_name = $1
+				AND end_reason IS NULL
+				AND end_date_time >= (NOW() - INTERVAL '%d seconds')
+			ORDER BY start_date_time DESC
+			LIMIT 1;`, minusNSeconds),
+			dto.ComputerName,
+		).Scan(&sessionID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return 0, &response.ErrNoSession
+			}
+			return 0, fmt.Errorf("query: %w", err)
+		}
+	}
+
+	var id int64
+	if err := tx.QueryRow(ctx2, `INSERT INTO
+		session.commands (comp_name, session_id, command, message, next_ping_sec, created_by)
+		VALUES (NULLIF($1, ''), $2::uuid, $3, NULLIF($4, ''), NULLIF($5, 0), $6)
+		RETURNING id;`,
+		dto.ComputerName,
+		sessionID,
+		dto.Command,
+		dto.Message,
+		int(dto.NextPing.Seconds()),
+		dto.Admin,
+	).Scan(&id); err != nil {
+		return 0, customErr("exec", err)
+	}
+
+	target := dto.ComputerName
+	if target == "" {
+		target = dto.SessionID
+	}
+	if err := insertAudit(ctx2, tx, dto.Admin, "command_"+dto.Command, target, dto.Message); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *storage) GetCommands(ctx context.Context, dto *domain.CommandFilter) ([]response.CommandInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, command, COALESCE(message, ''), COALESCE(next_ping_sec, 0),
+			COALESCE(comp_name, ''), COALESCE(session_id::text, ''),
+			created_by, created_at, delivered_at, acked_at
+		FROM session.commands
+		WHERE ($1 = '' OR comp_name = $1)
+			AND ($2 = '' OR session_id::text = $2)
+			AND (NOT $3 OR acked_at IS NULL)
+		ORDER BY id DESC
+		LIMIT 500;`,
+		dto.ComputerName,
+		dto.SessionID,
+		dto.PendingOnly,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	commands := make([]response.CommandInfo, 0, 50)
+
+	for rows.Next() {
+		command := response.CommandInfo{}
+		if err := rows.Scan(
+			&command.ID,
+			&command.Command.Command,
+			&command.Message,
+			&command.NextPingSeconds,
+			&command.ComputerName,
+			&command.SessionID,
+			&command.CreatedBy,
+			&command.CreatedAt,
+			&command.DeliveredAt,
+			&command.AckedAt,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		commands = append(commands, command)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return commands, nil
+}
+
+// DeliverCommands returns not yet acknowledged commands of the session and marks them as delivered.
+// commands are delivered on every ping until the computer acknowledges them.
+func (s *storage) DeliverCommands(ctx context.Context, sessionID string) ([]response.Command, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`UPDATE session.commands
+		SET delivered_at = COALESCE(delivered_at, NOW())
+		WHERE `+pendingCommandsFilter+`
+		RETURNING id, command, COALESCE(message, ''), COALESCE(next_ping_sec, 0);`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, customErr("query", err)
+	}
+	defer rows.Close()
+
+	commands := make([]response.Command, 0, 5)
+
+	for rows.Next() {
+		command := response.Command{}
+		if err := rows.Scan(
+			&command.ID,
+			&command.Command,
+			&command.Message,
+			&command.NextPingSeconds,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		commands = append(commands, command)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	sort.Slice(commands, func(i, j int) bool { return commands[i].ID < commands[j].ID })
+
+	return commands, nil
+}
+
+func (s *storage) AckCommands(ctx context.Context, sessionID string, ids []int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx,
+		`UPDATE session.commands
+		SET acked_at = NOW()
+		WHERE `+pendingCommandsFilter+`
+			AND id = ANY($2);`,
+		sessionID,
+		ids,
+	); err != nil {
+		return customErr("exec", err)
+	}
+
+	return nil
+}
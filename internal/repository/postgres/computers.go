@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const computerColumns = `comp_name, COALESCE(status, ''), COALESCE(building, ''), COALESCE(floor, ''),
+	COALESCE(zone, ''), COALESCE(seat_row, ''), COALESCE(seat, ''), pos_x, pos_y`
+
+const computersFilter = `($1 = '' OR comp_name LIKE $1)
+	AND ($2 = '' OR status = $2)`
+
+func (s *storage) GetComputers(ctx context.Context, dto *domain.ComputerFilter) (*response.Computers, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	pattern := ""
+	if dto.Prefix != "" {
+		pattern = escapeLike(dto.Prefix) + "%"
+	}
+
+	computers := response.Computers{}
+
+	if err := s.pool.QueryRow(ctx,
+		`SELECT COUNT(*)
+		FROM session.computers
+		WHERE `+computersFilter+`;`,
+		pattern,
+		dto.Status,
+	).Scan(&computers.Total); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+computerColumns+`
+		FROM session.computers
+		WHERE `+computersFilter+`
+		ORDER BY comp_name
+		LIMIT $3 OFFSET $4;`,
+		pattern,
+		dto.Status,
+		dto.Limit,
+		dto.Offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	if computers.Computers, err = scanComputers(rows, dto.Limit); err != nil {
+		return nil, err
+	}
+
+	return &computers, nil
+}
+
+// GetFloorComputers returns not retired computers of the building and floor (all if empty).
+func (s *storage) GetFloorComputers(ctx context.Context, dto *domain.FloorFilter) ([]response.Computer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+computerColumns+`
+		FROM session.computers
+		WHERE ($1 = '' OR building = $1)
+			AND ($2 = '' OR floor = $2)
+			AND status IS DISTINCT FROM $3
+		ORDER BY building, floor, zone, seat_row, seat, comp_name;`,
+		dto.Building,
+		dto.Floor,
+		request.ComputerStatusRetired,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	return scanComputers(rows, 250)
+}
+
+func (s *storage) GetComputer(ctx context.Context, compName string) (*response.Computer, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	computer := response.Computer{}
+
+	if err := scanComputer(s.pool.QueryRow(ctx,
+		`SELECT `+computerColumns+`
+		FROM session.computers
+		WHERE comp_name = $1;`,
+		compName,
+	), &computer); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &response.ErrNotFound
+		}
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	return &computer, nil
+}
+
+// UpdateComputer changes only not nil fields, empty string clears the text field of the location,
+// the position (x, y) is cleared by ClearPosition.
+func (s *storage) UpdateComputer(ctx context.Context, dto *domain.ComputerUpdate) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if tag, err := s.pool.Exec(ctx, `UPDATE session.computers
+		SET status = COALESCE($2, status),
+			building = NULLIF(COALESCE($3, building), ''),
+			floor = NULLIF(COALESCE($4, floor), ''),
+			zone = NULLIF(COALESCE($5, zone), ''),
+			seat_row = NULLIF(COALESCE($6, seat_row), ''),
+			seat = NULLIF(COALESCE($7, seat), ''),
+			pos_x = CASE WHEN $10 THEN NULL ELSE COALESCE($8, pos_x) END,
+			pos_y = CASE WHEN $10 THEN NULL ELSE COALESCE($9, pos_y) END
+		WHERE comp_name = $1;`,
+		dto.Name,
+		dto.Status,
+		dto.Building,
+		dto.Floor,
+		dto.Zone,
+		dto.Row,
+		dto.Seat,
+		dto.X,
+		dto.Y,
+		dto.ClearPosition,
+	); err != nil {
+		return customErr("exec", err)
+	} else if tag.RowsAffected() == 0 {
+		return &response.ErrNotFound
+	}
+
+	return nil
+}
+
+// RegisterComputer adds the unknown computer as unverified, the registered one is not changed.
+func (s *storage) RegisterComputer(ctx context.Context, compName string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, `INSERT INTO session.computers (comp_name, status)
+		VALUES ($1, $2)
+		ON CONFLICT (comp_name) DO NOTHING;`,
+		compName,
+		request.ComputerStatusUnverified,
+	); err != nil {
+		return customErr("exec", err)
+	}
+
+	return nil
+}
+
+// RetireComputer marks the computer as retired, sessions history is kept.
+func (s *storage) RetireComputer(ctx context.Context, compName string) error {
+	status := request.ComputerStatusRetired
+	return s.UpdateComputer(ctx, &domain.ComputerUpdate{
+		Name:   compName,
+		Status: &status,
+	})
+}
+
+func scanComputer(row pgx.Row, computer *response.Computer) error {
+	return row.Scan(
+		&computer.ComputerName,
+		&computer.Status,
+		&computer.Building,
+		&computer.Floor,
+		&computer.Zone,
+		&computer.Row,
+		&computer.Seat,
+		&computer.X,
+		&computer.Y,
+	)
+}
+
+func scanComputers(rows pgx.Rows, capacity int) ([]response.Computer, error) {
+	computers := make([]response.Computer, 0, capacity)
+
+	for rows.Next() {
+		computer := response.Computer{}
+		if err := scanComputer(rows, &computer); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		computers = append(computers, computer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return computers, nil
+}
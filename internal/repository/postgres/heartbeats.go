@@ -0,0 +1,313 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func insertHeartbeat(ctx context.Context, tx pgx.Tx, hb *domain.Heartbeat) error {
+	if _, err := tx.Exec(ctx, `INSERT INTO session.heartbeats (session_id, type, session_type, login, comp_name,
+			ip_addr, next_ping_sec, date_time, client_date_time, server_date_time, idle_sec, input_events, end_reason)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9, $10, $11, $12, NULLIF($13, ''));`,
+		hb.SessionID,
+		hb.Type,
+		hb.SessionType,
+		hb.Login,
+		hb.ComputerName,
+		hb.IPAddress,
+		int(hb.NextPing.Seconds()),
+		hb.DateTime,
+		hb.ClientDateTime,
+		hb.ServerDateTime,
+		int(hb.Idle.Seconds()),
+		hb.InputEvents,
+		hb.EndReason,
+	); err != nil {
+		return customErr("heartbeat: exec: insert", err)
+	}
+	return nil
+}
+
+// RebuildSessions recomputes in_campus, activity and segments rows of the sessions
+// started in the date range by replaying their heartbeats.
+func (s *storage) RebuildSessions(ctx context.Context, dto *domain.Rebuild) (*response.Rebuild, error) {
+	ctx2, cancel := context.WithTimeout(ctx, 600*time.Second)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("RebuildSessions: rollback: %s", err.Error())
+		}
+	}()
+
+	// triggers accept recomputed values as is (see migration 000015)
+	if _, err := tx.Exec(ctx2, `SET LOCAL session_manager.rebuild = 'on';`); err != nil {
+		return nil, customErr("exec: set", err)
+	}
+
+	rows, err := tx.Query(ctx2,
+		`SELECT session_id, type, session_type, next_ping_sec, date_time,
+			idle_sec, input_events, COALESCE(end_reason, '')
+		FROM session.heartbeats
+		WHERE session_id IN (
+			SELECT session_id
+			FROM session.heartbeats
+			WHERE type = $1
+				AND DATE_TRUNC('day', date_time) >= $2::date
+				AND DATE_TRUNC('day', date_time) <= $3::date
+		)
+		ORDER BY session_id, id;`,
+		domain.HeartbeatSession,
+		dto.FromDate,
+		dto.ToDate,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	sessions := make([]*replayedSession, 0, 250)
+	var current *replayedSession
+
+	for rows.Next() {
+		hb := domain.Heartbeat{}
+		var nextPingSec, idleSec int
+		if err := rows.Scan(
+			&hb.SessionID,
+			&hb.Type,
+			&hb.SessionType,
+			&nextPingSec,
+			&hb.DateTime,
+			&idleSec,
+			&hb.InputEvents,
+			&hb.EndReason,
+		); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		hb.NextPing = time.Duration(nextPingSec) * time.Second
+		hb.Idle = time.Duration(idleSec) * time.Second
+
+		if current == nil || current.id != hb.SessionID {
+			current = newReplayedSession(hb.SessionID)
+			sessions = append(sessions, current)
+		}
+		current.apply(&hb)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	report := response.Rebuild{}
+
+	for _, session := range sessions {
+		if !session.started {
+			continue
+		}
+		if err := session.save(ctx2, tx); err != nil {
+			return nil, fmt.Errorf("session %s: %w", session.id, err)
+		}
+		report.Sessions++
+		report.Activities += len(session.activities)
+		report.Segments += len(session.segments)
+	}
+
+	if err := insertAudit(ctx2, tx, dto.Admin, "rebuild_sessions", "",
+		fmt.Sprintf("%s - %s: %d sessions", dto.FromDate.Format(time.DateOnly), dto.ToDate.Format(time.DateOnly), report.Sessions),
+	); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return &report, nil
+}
+
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+type segment struct {
+	interval
+	sessionType string
+	idleSec     int
+	inputEvents int
+}
+
+// replayedSession is the state of the session after applying its heartbeats
+// the same way CreateSession, CreateActivity, ResumeSession and EndSession do.
+type replayedSession struct {
+	id         string
+	started    bool
+	session    interval
+	endReason  string
+	activities map[string]*interval
+	segments   []*segment
+	last       map[string]*segment // last segment by session_type
+}
+
+func newReplayedSession(id string) *replayedSession {
+	return &replayedSession{
+		id:         id,
+		activities: make(map[string]*interval),
+		last:       make(map[string]*segment),
+	}
+}
+
+func (r *replayedSession) apply(hb *domain.Heartbeat) {
+	end := hb.DateTime.Add(hb.NextPing)
+
+	switch hb.Type {
+	case domain.HeartbeatSession:
+		r.started = true
+		r.session = interval{start: hb.DateTime, end: end}
+		r.extend("", hb.DateTime, end, 0, 0)
+	case domain.HeartbeatResume:
+		if end.After(r.session.end) {
+			r.session.end = end
+		}
+		r.extend("", hb.DateTime, end, 0, 0)
+	case domain.HeartbeatActivity:
+		if hb.SessionType != "" {
+			if activity, ok := r.activities[hb.SessionType]; ok {
+				activity.end = end
+			} else {
+				r.activities[hb.SessionType] = &interval{start: hb.DateTime, end: end}
+			}
+			r.extend(hb.SessionType, hb.DateTime, end, hb.Idle, hb.InputEvents)
+		}
+		if end.After(r.session.end) {
+			r.session.end = end
+		}
+		r.extend("", hb.DateTime, end, hb.Idle, hb.InputEvents)
+	case domain.HeartbeatEnd:
+		r.close(hb.DateTime, hb.EndReason)
+	}
+}
+
+// extend mirrors extendSegment
+func (r *replayedSession) extend(sessionType string, ping, end time.Time, idle time.Duration, inputEvents int) {
+	last, ok := r.last[sessionType]
+	if ok && !last.end.Add(segmentToleranceSeconds*time.Second).Before(ping) {
+		if end.After(last.end) {
+			last.end = end
+		}
+		last.idleSec += int(idle.Seconds())
+		last.inputEvents += inputEvents
+		return
+	}
+
+	seg := &segment{
+		interval:    interval{start: ping, end: end},
+		sessionType: sessionType,
+		idleSec:     int(idle.Seconds()),
+		inputEvents: inputEvents,
+	}
+	r.segments = append(r.segments, seg)
+	r.last[sessionType] = seg
+}
+
+// close mirrors endSession
+func (r *replayedSession) close(at time.Time, reason string) {
+	r.session.end = clip(r.session, at)
+	r.endReason = reason
+
+	for _, activity := range r.activities {
+		activity.end = clip(*activity, at)
+	}
+
+	segments := r.segments[:0]
+	for _, seg := range r.segments {
+		if seg.start.After(r.session.end) {
+			continue
+		}
+		if seg.end.After(r.session.end) {
+			seg.end = r.session.end
+		}
+		segments = append(segments, seg)
+	}
+	r.segments = segments
+}
+
+// clip returns GREATEST(start, LEAST(end, at))
+func clip(i interval, at time.Time) time.Time {
+	end := i.end
+	if at.Before(end) {
+		end = at
+	}
+	if end.Before(i.start) {
+		end = i.start
+	}
+	return end
+}
+
+func (r *replayedSession) save(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, `UPDATE session.in_campus
+		SET start_date_time = $1,
+			end_date_time = $2,
+			end_reason = NULLIF($3, '')
+		WHERE id = $4;`,
+		r.session.start,
+		r.session.end,
+		r.endReason,
+		r.id,
+	); err != nil {
+		return customErr("session: exec: update", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM session.activity WHERE session_id = $1;`, r.id); err != nil {
+		return customErr("activity: exec: delete", err)
+	}
+	for sessionType, activity := range r.activities {
+		if _, err := tx.Exec(ctx, `INSERT INTO session.activity (session_id, session_type, login,
+				start_date_time, end_date_time, end_reason)
+			SELECT id, $2, login, $3, $4, NULLIF($5, '')
+			FROM session.in_campus
+			WHERE id = $1;`,
+			r.id,
+			sessionType,
+			activity.start,
+			activity.end,
+			r.endReason,
+		); err != nil {
+			return customErr("activity: exec: insert", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM session.segments WHERE session_id = $1;`, r.id); err != nil {
+		return customErr("segment: exec: delete", err)
+	}
+	for _, seg := range r.segments {
+		if _, err := tx.Exec(ctx, `INSERT INTO session.segments (session_id, session_type, login,
+				start_date_time, end_date_time, idle_sec, input_events)
+			SELECT id, $2, login, $3, $4, $5, $6
+			FROM session.in_campus
+			WHERE id = $1;`,
+			r.id,
+			seg.sessionType,
+			seg.start,
+			seg.end,
+			seg.idleSec,
+			seg.inputEvents,
+		); err != nil {
+			return customErr("segment: exec: insert", err)
+		}
+	}
+
+	return nil
+}
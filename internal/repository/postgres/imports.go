@@ -0,0 +1,256 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// importChunk is the number of rows committed at once
+const importChunk = 500
+
+// ImportUsers creates users, updates status and adds them to the cohort row by row,
+// next returns io.EOF at the end.
+func (s *storage) ImportUsers(ctx context.Context, dto *domain.Import, next func() (*domain.UserImport, error)) (*response.ImportResults, error) {
+	cohorts := make(map[string]bool, 5) // exists or not
+
+	return s.importRows(ctx, dto, func(ctx context.Context, tx pgx.Tx) (*response.ImportResult, error) {
+		user, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		result := response.ImportResult{
+			Line:   user.Line,
+			Name:   user.Login,
+			Result: response.ResultUnchanged,
+		}
+
+		if user.Invalid != "" {
+			return invalidImport(result, user.Invalid), nil
+		}
+
+		if user.Cohort != "" {
+			exists, ok := cohorts[user.Cohort]
+			if !ok {
+				if err := tx.QueryRow(ctx,
+					`SELECT EXISTS (SELECT FROM session.cohorts WHERE name = $1);`,
+					user.Cohort,
+				).Scan(&exists); err != nil {
+					return nil, fmt.Errorf("query row: %w", err)
+				}
+				cohorts[user.Cohort] = exists
+			}
+			if !exists {
+				return invalidImport(result, "cohort not found"), nil
+			}
+		}
+
+		if tag, err := tx.Exec(ctx, `INSERT INTO public.users (login, status)
+			VALUES ($1, COALESCE(NULLIF($2, ''), $3))
+			ON CONFLICT (login) DO NOTHING;`,
+			user.Login,
+			user.Status,
+			request.UserStatusActive,
+		); err != nil {
+			return nil, customErr("exec", err)
+		} else if tag.RowsAffected() != 0 {
+			result.Result = response.ResultCreated
+		} else {
+			var deleted bool
+			if err := tx.QueryRow(ctx,
+				`SELECT deleted_at IS NOT NULL FROM public.users WHERE login = $1 FOR UPDATE;`,
+				user.Login,
+			).Scan(&deleted); err != nil {
+				return nil, fmt.Errorf("query row: %w", err)
+			}
+			if deleted {
+				return invalidImport(result, "user is deleted"), nil
+			}
+		}
+
+		if user.Status != "" && result.Result != response.ResultCreated {
+			if tag, err := tx.Exec(ctx, `UPDATE public.users
+				SET status = $2
+				WHERE login = $1 AND status IS DISTINCT FROM $2;`,
+				user.Login,
+				user.Status,
+			); err != nil {
+				return nil, customErr("exec", err)
+			} else if tag.RowsAffected() != 0 {
+				result.Result = response.ResultUpdated
+			}
+		}
+
+		if user.Cohort != "" {
+			if tag, err := tx.Exec(ctx, `INSERT INTO session.cohort_members (cohort, login)
+				VALUES ($1, $2)
+				ON CONFLICT (cohort, login) DO NOTHING;`,
+				user.Cohort,
+				user.Login,
+			); err != nil {
+				return nil, customErr("exec", err)
+			} else if tag.RowsAffected() != 0 && result.Result == response.ResultUnchanged {
+				result.Result = response.ResultUpdated
+			}
+		}
+
+		return &result, nil
+	})
+}
+
+// ImportComputers creates computers and updates their status and location row by row,
+// next returns io.EOF at the end.
+func (s *storage) ImportComputers(ctx context.Context, dto *domain.Import, next func() (*domain.ComputerImport, error)) (*response.ImportResults, error) {
+	return s.importRows(ctx, dto, func(ctx context.Context, tx pgx.Tx) (*response.ImportResult, error) {
+		computer, err := next()
+		if err != nil {
+			return nil, err
+		}
+
+		result := response.ImportResult{
+			Line:   computer.Line,
+			Name:   computer.Name,
+			Result: response.ResultUnchanged,
+		}
+
+		if computer.Invalid != "" {
+			return invalidImport(result, computer.Invalid), nil
+		}
+
+		if tag, err := tx.Exec(ctx, `INSERT INTO session.computers
+			(comp_name, status, building, floor, zone, seat_row, seat, pos_x, pos_y)
+			VALUES ($1, COALESCE($2, $10), $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (comp_name) DO NOTHING;`,
+			computer.Name,
+			computer.Status,
+			computer.Building,
+			computer.Floor,
+			computer.Zone,
+			computer.Row,
+			computer.Seat,
+			computer.X,
+			computer.Y,
+			request.ComputerStatusActive,
+		); err != nil {
+			return nil, customErr("exec", err)
+		} else if tag.RowsAffected() != 0 {
+			result.Result = response.ResultCreated
+			return &result, nil
+		}
+
+		if tag, err := tx.Exec(ctx, `UPDATE session.computers
+			SET status = COALESCE($2, status),
+				building = COALESCE($3, building),
+				floor = COALESCE($4, floor),
+				zone = COALESCE($5, zone),
+				seat_row = COALESCE($6, seat_row),
+				seat = COALESCE($7, seat),
+				pos_x = COALESCE($8, pos_x),
+				pos_y = COALESCE($9, pos_y)
+			WHERE comp_name = $1
+				AND (status, building, floor, zone, seat_row, seat, pos_x, pos_y)
+				IS DISTINCT FROM (COALESCE($2, status), COALESCE($3, building), COALESCE($4, floor),
+					COALESCE($5, zone), COALESCE($6, seat_row), COALESCE($7, seat),
+					COALESCE($8, pos_x), COALESCE($9, pos_y));`,
+			computer.Name,
+			computer.Status,
+			computer.Building,
+			computer.Floor,
+			computer.Zone,
+			computer.Row,
+			computer.Seat,
+			computer.X,
+			computer.Y,
+		); err != nil {
+			return nil, customErr("exec", err)
+		} else if tag.RowsAffected() != 0 {
+			result.Result = response.ResultUpdated
+		}
+
+		return &result, nil
+	})
+}
+
+// importRows imports rows until importRow returns io.EOF, committing every importChunk rows,
+// the dry run is done in one transaction rolled back at the end.
+// If the import fails, already committed chunks stay imported: their results are returned with the error.
+func (s *storage) importRows(ctx context.Context, dto *domain.Import, importRow func(ctx context.Context, tx pgx.Tx) (*response.ImportResult, error)) (*response.ImportResults, error) {
+	ctx2, cancel := context.WithTimeout(ctx, 600*time.Second)
+	defer cancel()
+
+	results := response.ImportResults{
+		DryRun:  dto.DryRun,
+		Results: make([]response.ImportResult, 0, 50),
+	}
+	// results of the committed chunks
+	committed := results
+	committed.Aborted = true
+	aborted := func(err error) (*response.ImportResults, error) {
+		if dto.DryRun {
+			return nil, err
+		}
+		return &committed, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("importRows: rollback: %s", err.Error())
+		}
+	}()
+
+	line := 0
+	for rows := 1; ; rows++ {
+		result, err := importRow(ctx2, tx)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return aborted(err)
+		}
+		results.Add(*result)
+		line = result.Line
+
+		if dto.DryRun || rows%importChunk != 0 {
+			continue
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return aborted(fmt.Errorf("commit tx: %w", err))
+		}
+		committed = results
+		committed.Aborted = true
+		committed.CommittedLine = line
+
+		nextTx, err := s.pool.Begin(ctx)
+		if err != nil {
+			return aborted(fmt.Errorf("begin tx: %w", err))
+		}
+		tx = nextTx
+	}
+
+	if !dto.DryRun {
+		if err = tx.Commit(ctx); err != nil {
+			return aborted(fmt.Errorf("commit tx: %w", err))
+		}
+	}
+
+	return &results, nil
+}
+
+func invalidImport(result response.ImportResult, reason string) *response.ImportResult {
+	result.Result = response.ResultInvalid
+	result.Reason = reason
+	return &result
+}
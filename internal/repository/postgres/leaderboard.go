@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+	"time"
+)
+
+// GetLeaderboard ranks all not deleted users by hours in the range in the requested order,
+// users without hours are ranked too.
+func (s *storage) GetLeaderboard(ctx context.Context, dto *domain.Leaderboard) (*response.Leaderboard, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	// session_type '' - segments of session itself, other - segments of activity.
+	// times are converted to the wall time of the report's timezone ($10),
+	// segments are clipped to the range [from_date, to_date + 1 day),
+	// idle time is shared in proportion to the clipped part of the segment
+	rows, err := s.pool.Query(ctx,
+		`WITH local_segments AS (
+			SELECT
+				login,
+				start_date_time AT TIME ZONE $10 AS start_date_time,
+				end_date_time AT TIME ZONE $10 AS end_date_time,
+				idle_sec
+			FROM session.segments
+			WHERE
+				session_type = $1
+				-- raw range for the index, padded by a day for the offset of the report's timezone
+				AND start_date_time < $3::date + 2
+				AND end_date_time > $2::date - 1
+		), clipped_segments AS (
+			SELECT
+				login,
+				EXTRACT(EPOCH FROM (LEAST(end_date_time, $3::date + 1)
+					- GREATEST(start_date_time, $2::date))) AS clipped_sec,
+				COALESCE(LEAST(idle_sec, EXTRACT(EPOCH FROM (end_date_time - start_date_time)))
+					/ NULLIF(EXTRACT(EPOCH FROM (end_date_time - start_date_time)), 0), 0) AS idle_ratio
+			FROM local_segments
+			WHERE
+				start_date_time < $3::date + 1
+				AND end_date_time > $2::date
+		), user_hours AS (
+			SELECT
+				u.login,
+				COALESCE(u.status, '') AS status,
+				COALESCE(SUM(s.clipped_sec), 0) / 3600 AS hours,
+				COALESCE(SUM(s.clipped_sec * s.idle_ratio), 0) / 3600 AS idle_hours
+			FROM public.users u
+			LEFT JOIN clipped_segments s ON s.login = u.login
+			WHERE
+				u.deleted_at IS NULL
+				AND ($4 = '' OR u.status = $4)
+				AND ($5 = '' OR u.login IN (SELECT login FROM session.cohort_members WHERE cohort = $5))
+			GROUP BY u.login
+		), ranked_hours AS (
+			SELECT *, CASE WHEN $6 THEN hours - idle_hours ELSE hours END AS rank_hours
+			FROM user_hours
+		)
+		SELECT
+			RANK() OVER (ORDER BY CASE WHEN $7 THEN rank_hours END, rank_hours DESC) AS rank,
+			login,
+			status,
+			hours,
+			idle_hours,
+			COUNT(*) OVER () AS total
+		FROM ranked_hours
+		ORDER BY CASE WHEN $7 THEN rank_hours END, rank_hours DESC, login
+		LIMIT $8 OFFSET $9;`,
+		dto.SessionType,
+		dto.FromDate,
+		dto.ToDate,
+		dto.Status,
+		dto.Cohort,
+		dto.ActiveOnly,
+		dto.Ascending,
+		dto.Limit,
+		dto.Offset,
+		dto.Location.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	leaderboard := response.Leaderboard{
+		Users: make([]response.LeaderboardUser, 0, dto.Limit),
+	}
+
+	for rows.Next() {
+		user := response.LeaderboardUser{}
+		var hours, idleHours float64
+		if err := rows.Scan(
+			&user.Rank,
+			&user.Login,
+			&user.Status,
+			&hours,
+			&idleHours,
+			&leaderboard.Total,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		user.Hours = float32(math.Round(hours*100) / 100)
+		user.ActiveHours = float32(math.Round((hours-idleHours)*100) / 100)
+		user.IdleHours = float32(math.Round(idleHours*100) / 100)
+		if dto.ActiveOnly {
+			user.Hours = user.ActiveHours
+		}
+		leaderboard.Users = append(leaderboard.Users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return &leaderboard, nil
+}
@@ -8,7 +8,8 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewPostgres(ctx context.Context) *pgxpool.Pool {
+// NewPostgres opens the pool, every connection of it works in the timezone of the campus
+func NewPostgres(ctx context.Context, timezone string) *pgxpool.Pool {
 	log.Println("[postgres-pool] init...")
 
 	connStr := os.Getenv("DATABASE_URL")
@@ -17,7 +18,15 @@ func NewPostgres(ctx context.Context) *pgxpool.Pool {
 		log.Fatalf("[postgres-pool] connection string is empty")
 	}
 
-	pool, err := pgxpool.New(ctx, connStr)
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		log.Fatalf("[postgres-pool] parse config error: %s", err)
+	}
+
+	log.Printf("[postgres-pool] set time zone: %s", timezone)
+	config.ConnConfig.RuntimeParams["timezone"] = timezone
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		log.Fatalf("[postgres-pool] init error: %s", err)
 	}
@@ -33,10 +42,5 @@ func NewPostgres(ctx context.Context) *pgxpool.Pool {
 	log.Println("[postgres-pool] check conn OK")
 	log.Println("[postgres-pool] init done")
 
-	log.Println("[postgres-pool] set time zone: Asia/Almaty")
-	if _, err = pool.Exec(ctx, "SET TIME ZONE 'Asia/Almaty'"); err != nil {
-		log.Fatalf("[postgres-pool] set time zone error: %s", err)
-	}
-
 	return pool
 }
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetSessionPolicy returns the policy for the status of the login or the 'default' one.
+func (s *storage) GetSessionPolicy(ctx context.Context, login string) (*domain.SessionPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	policy := domain.SessionPolicy{}
+
+	if err := s.pool.QueryRow(ctx,
+		`SELECT status, max_sessions, COALESCE(comp_pattern, '')
+		FROM session.status_policies
+		WHERE status = (SELECT status FROM public.users WHERE login = $1)
+			OR status = $2
+		ORDER BY status = $2
+		LIMIT 1;`,
+		login,
+		domain.DefaultPolicyStatus,
+	).Scan(
+		&policy.Status,
+		&policy.MaxSessions,
+		&policy.ComputerPattern,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &domain.SessionPolicy{
+				Status:      domain.DefaultPolicyStatus,
+				MaxSessions: 1,
+			}, nil
+		}
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	return &policy, nil
+}
+
+func (s *storage) GetSessionPolicies(ctx context.Context) ([]response.SessionPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT status, max_sessions, COALESCE(comp_pattern, '')
+		FROM session.status_policies
+		ORDER BY status;`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	policies := make([]response.SessionPolicy, 0, 10)
+
+	for rows.Next() {
+		policy := response.SessionPolicy{}
+		if err := rows.Scan(
+			&policy.Status,
+			&policy.MaxSessions,
+			&policy.ComputerPattern,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return policies, nil
+}
+
+func (s *storage) SetSessionPolicy(ctx context.Context, dto *domain.SessionPolicy) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, `INSERT INTO
+		session.status_policies (status, max_sessions, comp_pattern)
+		VALUES ($1, $2, NULLIF($3, ''))
+		ON CONFLICT (status)
+		DO UPDATE SET
+		max_sessions = EXCLUDED.max_sessions,
+		comp_pattern = EXCLUDED.comp_pattern;`,
+		dto.Status,
+		dto.MaxSessions,
+		dto.ComputerPattern,
+	); err != nil {
+		return customErr("exec", err)
+	}
+
+	return nil
+}
@@ -18,14 +18,58 @@ import (
 )
 
 type Storage interface {
-	CreateUsers(ctx context.Context, req []request.User) (err error)
-	CreateComputers(ctx context.Context, req []request.Computer) (err error)
+	CreateUsers(ctx context.Context, req []request.User) ([]response.CreateResult, error)
+	CreateComputers(ctx context.Context, req []request.Computer) ([]response.CreateResult, error)
+	ImportUsers(ctx context.Context, dto *domain.Import, next func() (*domain.UserImport, error)) (*response.ImportResults, error)
+	ImportComputers(ctx context.Context, dto *domain.Import, next func() (*domain.ComputerImport, error)) (*response.ImportResults, error)
+	GetUsers(ctx context.Context, dto *domain.UserFilter) (*response.Users, error)
+	GetUser(ctx context.Context, login string) (*response.User, error)
+	UpdateUser(ctx context.Context, dto *domain.UserUpdate) error
+	DeleteUser(ctx context.Context, login string) error
+	SyncUsers(ctx context.Context, dto *domain.UserSync) (*response.UserSync, error)
+	SaveUserSyncError(ctx context.Context, dto *domain.UserSync, syncErr error) error
+	GetUserSyncs(ctx context.Context) ([]response.UserSync, error)
+	GetComputers(ctx context.Context, dto *domain.ComputerFilter) (*response.Computers, error)
+	GetComputer(ctx context.Context, compName string) (*response.Computer, error)
+	GetFloorComputers(ctx context.Context, dto *domain.FloorFilter) ([]response.Computer, error)
+	RegisterComputer(ctx context.Context, compName string) error
+	UpdateComputer(ctx context.Context, dto *domain.ComputerUpdate) error
+	RetireComputer(ctx context.Context, compName string) error
 	CreateSession(ctx context.Context, dto *domain.Session) error
 	CreateActivity(ctx context.Context, dto *domain.Activity) error
+	EndSession(ctx context.Context, dto *domain.SessionEnd) error
+	TerminateSessions(ctx context.Context, dto *domain.SessionTerminate) ([]response.Session, error)
+	CreateCommand(ctx context.Context, dto *domain.Command) (int64, error)
+	GetCommands(ctx context.Context, dto *domain.CommandFilter) ([]response.CommandInfo, error)
+	DeliverCommands(ctx context.Context, sessionID string) ([]response.Command, error)
+	AckCommands(ctx context.Context, sessionID string, ids []int64) error
 	GetOnlineDashboard(ctx context.Context) ([]response.Session, error)
 	IsSessionExists(ctx context.Context, login string) ([]response.Session, error)
-	GetUserActivityByMonth(ctx context.Context, dto *domain.UserActivity) (*response.UserActivity, error)
-	GetUserActivityByDate(ctx context.Context, dto *domain.UserActivity) (*response.UserActivity, error)
+	GetComputerSessions(ctx context.Context, compName string) ([]response.Session, error)
+	GetResumableSession(ctx context.Context, login, compName string, grace time.Duration) (*response.Session, error)
+	ResumeSession(ctx context.Context, id string, dto *domain.Session) error
+	GetSessionPolicy(ctx context.Context, login string) (*domain.SessionPolicy, error)
+	GetSessionPolicies(ctx context.Context) ([]response.SessionPolicy, error)
+	SetSessionPolicy(ctx context.Context, dto *domain.SessionPolicy) error
+	GetZones(ctx context.Context) ([]response.Zone, error)
+	SetZone(ctx context.Context, dto *domain.Zone) error
+	DeleteZone(ctx context.Context, name string) error
+	SetClockSkew(ctx context.Context, compName, sessionID string, skew time.Duration) error
+	GetClockSkews(ctx context.Context, dto *domain.ClockSkewFilter) ([]response.ComputerClock, error)
+	RebuildSessions(ctx context.Context, dto *domain.Rebuild) (*response.Rebuild, error)
+	GetUserActivityByMonth(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error)
+	GetUserActivityByDate(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error)
+	GetUserActivityByWeek(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error)
+	GetUserActivityByYear(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error)
+	GetUserActivityByWeekday(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error)
+	GetUserActivityByHour(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error)
+	GetLeaderboard(ctx context.Context, dto *domain.Leaderboard) (*response.Leaderboard, error)
+	CreateCohort(ctx context.Context, dto *domain.Cohort) error
+	GetCohorts(ctx context.Context) ([]response.Cohort, error)
+	GetCohort(ctx context.Context, name string) (*response.Cohort, error)
+	DeleteCohort(ctx context.Context, name string) error
+	AddCohortMembers(ctx context.Context, dto *domain.CohortMembers) (*response.CohortMembers, error)
+	RemoveCohortMembers(ctx context.Context, dto *domain.CohortMembers) (*response.CohortMembers, error)
 }
 
 func NewStorage(pool *pgxpool.Pool) Storage {
@@ -41,74 +85,97 @@ const (
 	// by subtracting n-seconds from the current time when checking the online session.
 	//also affects how quickly a user can login again. so the interval should not be long (no more than 60 seconds).
 	minusNSeconds = 10
-)
 
-func (s *storage) CreateUsers(ctx context.Context, req []request.User) (err error) {
-	batch := &pgx.Batch{}
+	// a ping arriving later than the end of the last segment plus n-seconds opens a new segment,
+	// so the time the computer was offline is not counted.
+	segmentToleranceSeconds = 30
+)
 
+// CreateUsers returns result for every user, users must be validated before.
+func (s *storage) CreateUsers(ctx context.Context, req []request.User) ([]response.CreateResult, error) {
+	names := make([]string, 0, len(req))
 	for _, user := range req {
-		if user.Name == "" {
-			continue
-		}
-		batch.Queue(`INSERT INTO public.users (login) 
-		VALUES ($1) 
-		ON CONFLICT (login) DO NOTHING`,
-			user.Name,
-		)
+		names = append(names, user.Name)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 180*time.Second)
-	defer cancel()
-
-	results := s.pool.SendBatch(ctx, batch)
-	defer results.Close()
+	return s.createNames(ctx, `INSERT INTO public.users (login) 
+		VALUES ($1) 
+		ON CONFLICT (login) DO NOTHING
+		RETURNING login`,
+		names,
+	)
+}
 
-	for i := 0; i < len(req); i++ {
-		if _, err2 := results.Exec(); err2 != nil {
-			err = errors.Join(err, err2)
-		}
+// CreateComputers returns result for every computer, computers must be validated before.
+func (s *storage) CreateComputers(ctx context.Context, req []request.Computer) ([]response.CreateResult, error) {
+	names := make([]string, 0, len(req))
+	for _, computer := range req {
+		names = append(names, computer.Name)
 	}
 
-	return err
+	return s.createNames(ctx, `INSERT INTO session.computers (comp_name) 
+		VALUES ($1) 
+		ON CONFLICT (comp_name) DO NOTHING
+		RETURNING comp_name`,
+		names,
+	)
 }
 
-func (s *storage) CreateComputers(ctx context.Context, req []request.Computer) (err error) {
+// createNames inserts every name by the query in one batch,
+// the query returns a row if the name is created and nothing if it already exists.
+func (s *storage) createNames(ctx context.Context, query string, names []string) (results []response.CreateResult, err error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
 	batch := &pgx.Batch{}
 
-	for _, computer := range req {
-		if computer.Name == "" {
-			continue
-		}
-		batch.Queue(`INSERT INTO session.computers (comp_name) 
-		VALUES ($1) 
-		ON CONFLICT (comp_name) DO NOTHING`,
-			computer.Name,
-		)
+	for _, name := range names {
+		batch.Queue(query, name)
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, 180*time.Second)
 	defer cancel()
 
-	results := s.pool.SendBatch(ctx, batch)
-	defer results.Close()
+	batchResults := s.pool.SendBatch(ctx, batch)
+	defer batchResults.Close()
 
-	for i := 0; i < len(req); i++ {
-		if _, err2 := results.Exec(); err2 != nil {
-			err = errors.Join(err, err2)
+	results = make([]response.CreateResult, 0, len(names))
+
+	for _, name := range names {
+		result := response.CreateResult{Name: name, Result: response.ResultCreated}
+		if err2 := batchResults.QueryRow().Scan(&result.Name); err2 != nil {
+			if !errors.Is(err2, pgx.ErrNoRows) {
+				err = errors.Join(err, customErr("", err2))
+				continue
+			}
+			result.Result = response.ResultExists
 		}
+		results = append(results, result)
 	}
 
-	return err
+	return results, err
 }
 
 func (s *storage) CreateSession(ctx context.Context, dto *domain.Session) error {
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	ctx2, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("CreateSession: rollback: %s", err.Error())
+		}
+	}()
+
 	// start session
-	if _, err := s.pool.Exec(ctx, `INSERT INTO 
-		session.in_campus (id, comp_name, ip_addr, login, next_ping_sec, start_date_time, end_date_time) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7);`,
+	if _, err := tx.Exec(ctx2, `INSERT INTO 
+		session.in_campus (id, comp_name, ip_addr, login, next_ping_sec, start_date_time, end_date_time,
+			client_date_time, server_date_time, clock_skewed) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);`,
 		dto.ID,
 		dto.ComputerName,
 		dto.IPAddress,
@@ -116,10 +183,36 @@ func (s *storage) CreateSession(ctx context.Context, dto *domain.Session) error
 		int(dto.NextPing.Seconds()),
 		dto.StartDateTime,
 		dto.EndDateTime,
+		dto.ClientDateTime,
+		dto.ServerDateTime,
+		dto.Skewed,
 	); err != nil {
 		return customErr("exec", err)
 	}
 
+	// first segment
+	if err := extendSegment(ctx2, tx, dto.ID, "", dto.Login, dto.StartDateTime, dto.EndDateTime, 0, 0); err != nil {
+		return err
+	}
+
+	if err := insertHeartbeat(ctx2, tx, &domain.Heartbeat{
+		SessionID:      dto.ID,
+		Type:           domain.HeartbeatSession,
+		Login:          dto.Login,
+		ComputerName:   dto.ComputerName,
+		IPAddress:      dto.IPAddress,
+		NextPing:       dto.NextPing,
+		DateTime:       dto.StartDateTime,
+		ClientDateTime: dto.ClientDateTime,
+		ServerDateTime: dto.ServerDateTime,
+	}); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
 	return nil
 }
 
@@ -127,68 +220,283 @@ func (s *storage) CreateActivity(ctx context.Context, dto *domain.Activity) erro
 	ctx2, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
-	updateSessionEndQuery := `UPDATE session.in_campus
-	SET end_date_time = $1
-	WHERE id = $2;`
-
-	// -------------- if only session
-	if dto.SessionType == "" {
-		if tag, err := s.pool.Exec(ctx2, updateSessionEndQuery,
-			dto.EndDateTime,
-			dto.SessionID,
-		); err != nil {
-			return customErr("session: exec: update", err)
-		} else if tag.RowsAffected() == 0 {
-			return &response.ErrNotFound
-		}
-		return nil
-	}
-
-	// -------------- if other activity [on zero platforn and etc...]
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 	defer func() {
-		if err = tx.Rollback(ctx); err != nil {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			log.Printf("CreateActivity: rollback: %s", err.Error())
 		}
 	}()
 
-	// start activity
-	if _, err := tx.Exec(ctx2,
-		`INSERT INTO session.activity (session_id, session_type, login, start_date_time, end_date_time)
+	// -------------- if other activity [on zero platforn and etc...]
+	if dto.SessionType != "" {
+		// start activity
+		if _, err := tx.Exec(ctx2,
+			`INSERT INTO session.activity (session_id, session_type, login, start_date_time, end_date_time)
 			VALUES ($1, $2, $3, $4, $5)
 			ON CONFLICT (session_id, session_type)
 			DO UPDATE SET
-			end_date_time = EXCLUDED.end_date_time;`,
-		dto.SessionID,
-		dto.SessionType,
-		dto.Login,
-		dto.StartDateTime,
-		dto.EndDateTime,
-	); err != nil {
-		return customErr("activity: exec: insert", err)
+			end_date_time = EXCLUDED.end_date_time
+			WHERE session.activity.end_reason IS NULL;`,
+			dto.SessionID,
+			dto.SessionType,
+			dto.Login,
+			dto.StartDateTime,
+			dto.EndDateTime,
+		); err != nil {
+			return customErr("activity: exec: insert", err)
+		}
+
+		if err := extendSegment(ctx2, tx, dto.SessionID, dto.SessionType, dto.Login, dto.StartDateTime, dto.EndDateTime, dto.Idle, dto.InputEvents); err != nil {
+			return err
+		}
 	}
 
-	// also update session end_date_time
-	if tag, err := tx.Exec(ctx2, updateSessionEndQuery,
+	// -------------- session itself
+	if tag, err := tx.Exec(ctx2, `UPDATE session.in_campus
+		SET end_date_time = $1,
+			client_date_time = $3,
+			server_date_time = $4,
+			clock_skewed = clock_skewed OR $5
+		WHERE id = $2 AND end_reason IS NULL;`,
 		dto.EndDateTime,
 		dto.SessionID,
+		dto.ClientDateTime,
+		dto.ServerDateTime,
+		dto.Skewed,
 	); err != nil {
-		return customErr("activity: exec: update", err)
+		return customErr("session: exec: update", err)
 	} else if tag.RowsAffected() == 0 {
 		return &response.ErrNotFound
 	}
 
-	err = tx.Commit(ctx)
+	if err := extendSegment(ctx2, tx, dto.SessionID, "", dto.Login, dto.StartDateTime, dto.EndDateTime, dto.Idle, dto.InputEvents); err != nil {
+		return err
+	}
+
+	if err := insertHeartbeat(ctx2, tx, &domain.Heartbeat{
+		SessionID:      dto.SessionID,
+		Type:           domain.HeartbeatActivity,
+		SessionType:    dto.SessionType,
+		Login:          dto.Login,
+		IPAddress:      dto.IPAddress,
+		NextPing:       dto.EndDateTime.Sub(dto.StartDateTime),
+		DateTime:       dto.StartDateTime,
+		ClientDateTime: dto.ClientDateTime,
+		ServerDateTime: dto.ServerDateTime,
+		Idle:           dto.Idle,
+		InputEvents:    dto.InputEvents,
+	}); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// extendSegment moves the end of the last segment of the session (or its activity) to end,
+// or opens a new segment if the ping came after the last segment ended (the computer was offline).
+func extendSegment(ctx context.Context, tx pgx.Tx, sessionID, sessionType, login string, ping, end time.Time, idle time.Duration, inputEvents int) error {
+	query := fmt.Sprintf(`WITH last AS (
+		SELECT id, end_date_time
+		FROM session.segments
+		WHERE session_id = $1 AND session_type = $2
+		ORDER BY start_date_time DESC
+		LIMIT 1
+		FOR UPDATE
+	), extended AS (
+		UPDATE session.segments s
+		SET end_date_time = GREATEST(s.end_date_time, $5),
+			idle_sec = s.idle_sec + $6,
+			input_events = s.input_events + $7
+		FROM last
+		WHERE s.id = last.id
+			AND last.end_date_time + INTERVAL '%d seconds' >= $4
+		RETURNING s.id
+	)
+	INSERT INTO session.segments (session_id, session_type, login, start_date_time, end_date_time, idle_sec, input_events)
+	SELECT $1, $2, $3, $4, $5, $6, $7
+	WHERE NOT EXISTS (SELECT 1 FROM extended);`, segmentToleranceSeconds)
+
+	if _, err := tx.Exec(ctx, query,
+		sessionID,
+		sessionType,
+		login,
+		ping,
+		end,
+		int(idle.Seconds()),
+		inputEvents,
+	); err != nil {
+		return customErr("segment: exec", err)
+	}
+
+	return nil
+}
+
+func (s *storage) EndSession(ctx context.Context, dto *domain.SessionEnd) error {
+	ctx2, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("EndSession: rollback: %s", err.Error())
+		}
+	}()
+
+	if err := endSession(ctx2, tx, dto); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit tx: %w", err)
 	}
 
 	return nil
 }
 
+func (s *storage) TerminateSessions(ctx context.Context, dto *domain.SessionTerminate) ([]response.Session, error) {
+	ctx2, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("TerminateSessions: rollback: %s", err.Error())
+		}
+	}()
+
+	filter, arg := "login = $1", dto.Login
+	if dto.SessionID != "" {
+		filter, arg = "id = $1", dto.SessionID
+	}
+
+	query := fmt.Sprintf(`SELECT id, comp_name, ip_addr, login, start_date_time, end_date_time
+	FROM session.in_campus
+	WHERE %s
+		AND end_reason IS NULL
+		AND end_date_time >= (NOW() - INTERVAL '%d seconds')
+	FOR UPDATE;`, filter, minusNSeconds)
+
+	rows, err := tx.Query(ctx2, query, arg)
+	if err != nil {
+		return nil, customErr("query", err)
+	}
+	sessions, err := scanSessions(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, &response.ErrNotFound
+	}
+
+	for _, session := range sessions {
+		if err := endSession(ctx2, tx, &domain.SessionEnd{
+			ID:          session.ID,
+			Reason:      dto.Reason,
+			EndDateTime: dto.EndDateTime,
+		}); err != nil {
+			return nil, err
+		}
+		if err := insertAudit(ctx2, tx, dto.Admin, "terminate_session", session.ID, dto.Comment); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// endSession closes the session and its open activities at dto.EndDateTime.
+// the end is never moved forward: if the session already expired, it keeps its last end_date_time.
+func endSession(ctx context.Context, tx pgx.Tx, dto *domain.SessionEnd) error {
+	var end time.Time
+	var login, compName string
+	err := tx.QueryRow(ctx, `UPDATE session.in_campus
+		SET end_date_time = GREATEST(start_date_time, LEAST(end_date_time, $1)),
+			end_reason = $2
+		WHERE id = $3 AND end_reason IS NULL
+		RETURNING end_date_time, login, comp_name;`,
+		dto.EndDateTime,
+		dto.Reason,
+		dto.ID,
+	).Scan(&end, &login, &compName)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return customErr("session: exec: update", err)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		var ended bool
+		if err := tx.QueryRow(ctx, `SELECT end_reason IS NOT NULL
+			FROM session.in_campus
+			WHERE id = $1;`,
+			dto.ID,
+		).Scan(&ended); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &response.ErrNotFound
+			}
+			return customErr("session: query row", err)
+		}
+		if ended {
+			return &response.ErrSessionEnded
+		}
+		return &response.ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE session.activity
+		SET end_date_time = GREATEST(start_date_time, LEAST(end_date_time, $1)),
+			end_reason = $2
+		WHERE session_id = $3 AND end_reason IS NULL;`,
+		dto.EndDateTime,
+		dto.Reason,
+		dto.ID,
+	); err != nil {
+		return customErr("activity: exec: update", err)
+	}
+
+	// nothing is counted after the end of session
+	if _, err := tx.Exec(ctx, `DELETE FROM session.segments
+		WHERE session_id = $1 AND start_date_time > $2;`,
+		dto.ID,
+		end,
+	); err != nil {
+		return customErr("segment: exec: delete", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE session.segments
+		SET end_date_time = $2
+		WHERE session_id = $1 AND end_date_time > $2;`,
+		dto.ID,
+		end,
+	); err != nil {
+		return customErr("segment: exec: update", err)
+	}
+
+	return insertHeartbeat(ctx, tx, &domain.Heartbeat{
+		SessionID:      dto.ID,
+		Type:           domain.HeartbeatEnd,
+		Login:          login,
+		ComputerName:   compName,
+		DateTime:       dto.EndDateTime,
+		ClientDateTime: dto.EndDateTime,
+		ServerDateTime: time.Now(),
+		EndReason:      dto.Reason,
+	})
+}
+
 func (s *storage) GetOnlineDashboard(ctx context.Context) ([]response.Session, error) {
 	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
@@ -196,218 +504,279 @@ func (s *storage) GetOnlineDashboard(ctx context.Context) ([]response.Session, e
 	rows, err := s.pool.Query(ctx,
 		`SELECT id, comp_name, ip_addr, login, start_date_time, end_date_time
 		FROM session.in_campus
-		WHERE end_date_time >= NOW();`,
+		WHERE end_date_time >= NOW()
+			AND end_reason IS NULL;`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
 	defer rows.Close()
 
-	sessions := make([]response.Session, 0, 250)
+	return scanSessions(rows)
+}
 
-	for rows.Next() {
-		session := response.Session{}
-		if err := rows.Scan(
-			&session.ID,
-			&session.ComputerName,
-			&session.IPAddress,
-			&session.Login,
-			&session.StartDateTime,
-			&session.EndDateTime,
-		); err != nil {
-			return nil, fmt.Errorf("in iterate row: %w", err)
-		}
-		sessions = append(sessions, session)
+func (s *storage) GetUserActivityByMonth(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error) {
+	return queryUserActivity(ctx, s, dto, activityBucket{
+		unit: "month",
+		columns: `EXTRACT(YEAR FROM bucket) AS year,
+				EXTRACT(MONTH FROM bucket) AS month_number`,
+		groupBy: "year, month_number",
+		orderBy: "year DESC, month_number DESC",
+	}, func(activity *response.UserActivityByMonth) []any {
+		return []any{&activity.Year, &activity.MonthNumber}
+	})
+}
+
+// GetUserActivityByDate returns the date as the instant of midnight in the report's timezone.
+func (s *storage) GetUserActivityByDate(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error) {
+	users, err := queryUserActivity(ctx, s, dto, activityBucket{
+		unit:    "day",
+		columns: `bucket AT TIME ZONE $5 AS date`,
+		groupBy: "date",
+		orderBy: "date",
+	}, func(activity *response.UserActivityByDate) []any {
+		return []any{&activity.Date}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows at all: %w", err)
+	for _, user := range users {
+		activities := user.UserActivity.([]response.UserActivityByDate)
+		for i := range activities {
+			activities[i].Date = activities[i].Date.In(dto.Location)
+		}
 	}
 
-	return sessions, nil
+	return users, nil
 }
 
-func (s *storage) GetUserActivityByMonth(ctx context.Context, dto *domain.UserActivity) (*response.UserActivity, error) {
-	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
-	defer cancel()
-
-	var rows pgx.Rows
-	var err error
-
-	if dto.SessionType != "" {
-		// from activity
-		rows, err = s.pool.Query(ctx,
-			`WITH monthly_hours AS (
-				SELECT
-					login,
-					EXTRACT(YEAR FROM start_date_time) AS year,
-					EXTRACT(MONTH FROM start_date_time) AS month_number,
-					EXTRACT(EPOCH FROM (end_date_time - start_date_time)) / 3600 AS hours_calc
-				FROM session.activity
-				WHERE
-					login = $1
-					AND session_type = $2
-					AND DATE_TRUNC('day', start_date_time) >= $3::date
-					AND DATE_TRUNC('day', end_date_time) <= $4::date
-			)
-			SELECT 
-				login,
-				year,
-				month_number,
-				SUM(hours_calc) AS total_hours,
-				SUM(SUM(hours_calc)) OVER (PARTITION BY login) AS total_hours
-			FROM monthly_hours
-			GROUP BY login, year, month_number
-			ORDER BY year DESC, month_number DESC;`,
-			dto.Login,
-			dto.SessionType,
-			dto.FromDate,
-			dto.ToDate,
-		)
-	} else {
-		// from in_campus
-		rows, err = s.pool.Query(ctx,
-			`WITH monthly_hours AS (
-				SELECT
-					login,
-					EXTRACT(YEAR FROM start_date_time) AS year,
-					EXTRACT(MONTH FROM start_date_time) AS month_number,
-					EXTRACT(EPOCH FROM (end_date_time - start_date_time)) / 3600 AS hours_calc
-				FROM session.in_campus
-				WHERE
-					login = $1
-					AND DATE_TRUNC('day', start_date_time) >= $2::date
-					AND DATE_TRUNC('day', end_date_time) <= $3::date
-			)
-			SELECT 
-				login,
-				year,
-				month_number,
-				SUM(hours_calc) AS total_hours,
-				SUM(SUM(hours_calc)) OVER (PARTITION BY login) AS total_hours
-			FROM monthly_hours
-			GROUP BY login, year, month_number
-			ORDER BY year DESC, month_number DESC;`,
-			dto.Login,
-			dto.FromDate,
-			dto.ToDate,
-		)
+// GetUserActivityByWeek groups by ISO weeks, the week starts on Monday
+// (the instant of midnight in the report's timezone).
+func (s *storage) GetUserActivityByWeek(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error) {
+	users, err := queryUserActivity(ctx, s, dto, activityBucket{
+		unit: "week",
+		columns: `EXTRACT(ISOYEAR FROM bucket)::int AS iso_year,
+				EXTRACT(WEEK FROM bucket)::int AS week,
+				bucket AT TIME ZONE $5 AS week_start`,
+		groupBy: "iso_year, week, week_start",
+		orderBy: "week_start",
+	}, func(activity *response.UserActivityByWeek) []any {
+		return []any{&activity.Year, &activity.Week, &activity.StartDate}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("query: %w", err)
+	for _, user := range users {
+		activities := user.UserActivity.([]response.UserActivityByWeek)
+		for i := range activities {
+			activities[i].StartDate = activities[i].StartDate.In(dto.Location)
+		}
 	}
 
-	defer rows.Close()
+	return users, nil
+}
 
-	activities := make([]response.UserActivityByMonth, 0, 36)
-	var totalHours float64
-	var login string
+func (s *storage) GetUserActivityByYear(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error) {
+	return queryUserActivity(ctx, s, dto, activityBucket{
+		unit:    "year",
+		columns: `EXTRACT(YEAR FROM bucket) AS year`,
+		groupBy: "year",
+		orderBy: "year DESC",
+	}, func(activity *response.UserActivityByYear) []any {
+		return []any{&activity.Year}
+	})
+}
 
-	for rows.Next() {
-		activity := response.UserActivityByMonth{}
-		if err := rows.Scan(
-			&login,
-			&activity.Year,
-			&activity.MonthNumber,
-			&activity.Hours,
-			&totalHours,
-		); err != nil {
-			return nil, fmt.Errorf("in iterate row: %w", err)
-		}
-		activities = append(activities, activity)
+// GetUserActivityByWeekday sums hours of every day of the week in the range, Monday is 1.
+func (s *storage) GetUserActivityByWeekday(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error) {
+	users, err := queryUserActivity(ctx, s, dto, activityBucket{
+		unit:    "day",
+		columns: `EXTRACT(ISODOW FROM bucket)::int AS weekday`,
+		groupBy: "weekday",
+		orderBy: "weekday",
+	}, func(activity *response.UserActivityByWeekday) []any {
+		return []any{&activity.Weekday}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("rows at all: %w", err)
+	for _, user := range users {
+		activities := user.UserActivity.([]response.UserActivityByWeekday)
+		for i := range activities {
+			activities[i].Name = time.Weekday(activities[i].Weekday % 7).String()
+		}
 	}
 
-	return &response.UserActivity{
-		Login:        login,
-		TotalHours:   float32(math.Round(totalHours*100) / 100),
-		UserActivity: activities,
-	}, nil
+	return users, nil
+}
+
+// GetUserActivityByHour sums hours of every hour of the day in the range.
+func (s *storage) GetUserActivityByHour(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error) {
+	return queryUserActivity(ctx, s, dto, activityBucket{
+		unit:    "hour",
+		columns: `EXTRACT(HOUR FROM bucket)::int AS hour`,
+		groupBy: "hour",
+		orderBy: "hour",
+	}, func(activity *response.UserActivityByHour) []any {
+		return []any{&activity.Hour}
+	})
+}
+
+// activityBucket is the group of the activity report: segments are split by the unit
+// ('hour', 'day', 'week', 'month' or 'year'), columns are expressions of the bucket start with aliases
+type activityBucket struct {
+	unit    string
+	columns string
+	groupBy string
+	orderBy string
 }
 
-func (s *storage) GetUserActivityByDate(ctx context.Context, dto *domain.UserActivity) (*response.UserActivity, error) {
+// activityHours is the activity of the report with its bucket fields
+type activityHours[T any] interface {
+	*T
+	GetHours() *response.ActivityHours
+}
+
+// queryUserActivity returns activity of users grouped by the bucket, ordered by login,
+// bucketFields returns fields of the activity to scan the bucket columns.
+func queryUserActivity[T any, PT activityHours[T]](ctx context.Context, s *storage, dto *domain.UserActivity, bucket activityBucket, bucketFields func(PT) []any) ([]response.UserActivity, error) {
 	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
-	var rows pgx.Rows
-	var err error
-	if dto.SessionType != "" {
-		// from activity
-		rows, err = s.pool.Query(ctx,
-			`SELECT
+	unit := "INTERVAL '1 " + bucket.unit + "'"
+
+	// session_type '' - segments of session itself, other - segments of activity.
+	// times are converted to the wall time of the report's timezone ($5),
+	// segments are clipped to the range [from_date, to_date + 1 day) and split by buckets,
+	// idle time is shared in proportion to the piece of the segment
+	rows, err := s.pool.Query(ctx,
+		`WITH local_segments AS (
+			SELECT
 				login,
-				DATE_TRUNC('day', start_date_time) AS date,
-				SUM(EXTRACT(EPOCH FROM (end_date_time - start_date_time)) / 3600) AS hours,
-				SUM(EXTRACT(EPOCH FROM (end_date_time - start_date_time)) / 3600) OVER (PARTITION BY login) AS total_hours
-			FROM session.activity
+				start_date_time AT TIME ZONE $5 AS start_date_time,
+				end_date_time AT TIME ZONE $5 AS end_date_time,
+				idle_sec
+			FROM session.segments
 			WHERE
-				login = $1
+				login = ANY($1)
 				AND session_type = $2
-				AND DATE_TRUNC('day', start_date_time) >= $3::date
-				AND DATE_TRUNC('day', end_date_time) <= $4::date
-			GROUP BY login, date, start_date_time, end_date_time
-			ORDER BY date;`,
-			dto.Login,
-			dto.SessionType,
-			dto.FromDate,
-			dto.ToDate,
-		)
-	} else {
-		// from in_campus
-		rows, err = s.pool.Query(ctx,
-			`SELECT
+				-- raw range for the index, padded by a day for the offset of the report's timezone
+				AND start_date_time < $4::date + 2
+				AND end_date_time > $3::date - 1
+		), clipped_segments AS (
+			SELECT
 				login,
-				DATE_TRUNC('day', start_date_time) AS date,
-				SUM(EXTRACT(EPOCH FROM (end_date_time - start_date_time)) / 3600) AS hours,
-				SUM(EXTRACT(EPOCH FROM (end_date_time - start_date_time)) / 3600) OVER (PARTITION BY login) AS total_hours
-			FROM session.in_campus
+				GREATEST(start_date_time, $3::date) AS start_date_time,
+				LEAST(end_date_time, $4::date + 1) AS end_date_time,
+				COALESCE(LEAST(idle_sec, EXTRACT(EPOCH FROM (end_date_time - start_date_time)))
+					/ NULLIF(EXTRACT(EPOCH FROM (end_date_time - start_date_time)), 0), 0) AS idle_ratio
+			FROM local_segments
 			WHERE
-				login = $1
-				AND DATE_TRUNC('day', start_date_time) >= $2::date
-				AND DATE_TRUNC('day', end_date_time) <= $3::date
-			GROUP BY login, date, start_date_time, end_date_time
-			ORDER BY date;`,
-			dto.Login,
-			dto.FromDate,
-			dto.ToDate,
+				start_date_time < $4::date + 1
+				AND end_date_time > $3::date
+		), bucket_pieces AS (
+			SELECT
+				login,
+				bucket,
+				EXTRACT(EPOCH FROM (LEAST(end_date_time, bucket + `+unit+`)
+					- GREATEST(start_date_time, bucket))) / 3600 AS hours_calc,
+				idle_ratio
+			FROM clipped_segments
+			CROSS JOIN LATERAL generate_series(
+				DATE_TRUNC('`+bucket.unit+`', start_date_time), end_date_time, `+unit+`
+			) AS bucket
+		), bucket_hours AS (
+			SELECT
+				login,
+				`+bucket.columns+`,
+				hours_calc,
+				hours_calc * idle_ratio AS idle_calc
+			FROM bucket_pieces
+			WHERE hours_calc > 0
 		)
-	}
-
+		SELECT
+			login,
+			`+bucket.groupBy+`,
+			SUM(hours_calc) AS hours,
+			SUM(idle_calc) AS idle_hours,
+			SUM(SUM(hours_calc)) OVER (PARTITION BY login) AS total_hours,
+			SUM(SUM(idle_calc)) OVER (PARTITION BY login) AS total_idle_hours
+		FROM bucket_hours
+		GROUP BY login, `+bucket.groupBy+`
+		ORDER BY login, `+bucket.orderBy+`;`,
+		activityLogins(dto),
+		dto.SessionType,
+		dto.FromDate,
+		dto.ToDate,
+		dto.Location.String(),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
 	}
 	defer rows.Close()
 
-	activities := make([]response.UserActivityByDate, 0, 360)
-	var totalHours float64
+	users := make([]response.UserActivity, 0, len(activityLogins(dto)))
+	var totalHours, totalIdleHours float64
 	var login string
 
 	for rows.Next() {
-		activity := response.UserActivityByDate{}
-		if err := rows.Scan(
-			&login,
-			&activity.Date,
-			&activity.Hours,
-			&totalHours,
-		); err != nil {
+		activity := PT(new(T))
+		hours := activity.GetHours()
+
+		dest := append([]any{&login}, bucketFields(activity)...)
+		dest = append(dest, &hours.Hours, &hours.IdleHours, &totalHours, &totalIdleHours)
+
+		if err := rows.Scan(dest...); err != nil {
 			return nil, fmt.Errorf("in iterate row: %w", err)
 		}
-		activities = append(activities, activity)
+		hours.ActiveHours = hours.Hours - hours.IdleHours
+		if dto.ActiveOnly {
+			hours.Hours = hours.ActiveHours
+		}
+		users = appendUserActivity(users, dto, login, totalHours, totalIdleHours, *activity)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows at all: %w", err)
 	}
 
-	return &response.UserActivity{
-		Login:        login,
-		TotalHours:   float32(math.Round(totalHours*100) / 100),
-		UserActivity: activities,
-	}, nil
+	return users, nil
+}
+
+// activityLogins returns members of the cohort or the single login of the report.
+func activityLogins(dto *domain.UserActivity) []string {
+	if dto.Logins != nil {
+		return dto.Logins
+	}
+	return []string{dto.Login}
+}
+
+// appendUserActivity appends the activity to the last user of rows ordered by login,
+// or starts a new user.
+func appendUserActivity[T any](users []response.UserActivity, dto *domain.UserActivity, login string, totalHours, totalIdleHours float64, activity T) []response.UserActivity {
+	if len(users) == 0 || users[len(users)-1].Login != login {
+		users = append(users, *newUserActivity(dto, login, totalHours, totalIdleHours, []T{}))
+	}
+	user := &users[len(users)-1]
+	user.UserActivity = append(user.UserActivity.([]T), activity)
+	return users
+}
+
+func newUserActivity(dto *domain.UserActivity, login string, totalHours, totalIdleHours float64, activities any) *response.UserActivity {
+	activity := response.UserActivity{
+		Login:            login,
+		TotalHours:       float32(math.Round(totalHours*100) / 100),
+		TotalActiveHours: float32(math.Round((totalHours-totalIdleHours)*100) / 100),
+		TotalIdleHours:   float32(math.Round(totalIdleHours*100) / 100),
+		UserActivity:     activities,
+	}
+	if dto.ActiveOnly {
+		activity.TotalHours = activity.TotalActiveHours
+	}
+	return &activity
 }
 
 func (s *storage) IsSessionExists(ctx context.Context, login string) ([]response.Session, error) {
@@ -417,6 +786,7 @@ func (s *storage) IsSessionExists(ctx context.Context, login string) ([]response
 	query := fmt.Sprintf(`SELECT id, comp_name, ip_addr, login, start_date_time, end_date_time
 	FROM session.in_campus
 	WHERE login = $1
+		AND end_reason IS NULL
 		AND end_date_time >= (NOW() - INTERVAL '%d seconds');`, minusNSeconds)
 
 	rows, err := s.pool.Query(ctx, query, login)
@@ -428,6 +798,127 @@ func (s *storage) IsSessionExists(ctx context.Context, login string) ([]response
 	}
 	defer rows.Close()
 
+	return scanSessions(rows)
+}
+
+func (s *storage) GetComputerSessions(ctx context.Context, compName string) ([]response.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := fmt.Sprintf(`SELECT id, comp_name, ip_addr, login, start_date_time, end_date_time
+	FROM session.in_campus
+	WHERE comp_name = $1
+		AND end_reason IS NULL
+		AND end_date_time >= (NOW() - INTERVAL '%d seconds');`, minusNSeconds)
+
+	rows, err := s.pool.Query(ctx, query, compName)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSessions(rows)
+}
+
+// GetResumableSession returns the last not closed session of the login on the computer
+// that is still alive or ended less than grace ago, nil if there is no such session.
+func (s *storage) GetResumableSession(ctx context.Context, login, compName string, grace time.Duration) (*response.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, comp_name, ip_addr, login, start_date_time, end_date_time
+		FROM session.in_campus
+		WHERE login = $1
+			AND comp_name = $2
+			AND end_reason IS NULL
+			AND end_date_time >= (NOW() - make_interval(secs => $3))
+		ORDER BY end_date_time DESC
+		LIMIT 1;`,
+		login,
+		compName,
+		grace.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	sessions, err := scanSessions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+
+	return &sessions[0], nil
+}
+
+// ResumeSession extends the session by the new start request of the agent.
+func (s *storage) ResumeSession(ctx context.Context, id string, dto *domain.Session) error {
+	ctx2, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("ResumeSession: rollback: %s", err.Error())
+		}
+	}()
+
+	// end_date_time is never moved back
+	if _, err := tx.Exec(ctx2, `UPDATE session.in_campus
+		SET end_date_time = $1,
+			ip_addr = $2,
+			next_ping_sec = $3,
+			client_date_time = $5,
+			server_date_time = $6,
+			clock_skewed = clock_skewed OR $7
+		WHERE id = $4
+			AND end_reason IS NULL
+			AND end_date_time < $1;`,
+		dto.EndDateTime,
+		dto.IPAddress,
+		int(dto.NextPing.Seconds()),
+		id,
+		dto.ClientDateTime,
+		dto.ServerDateTime,
+		dto.Skewed,
+	); err != nil {
+		return customErr("exec", err)
+	}
+
+	// offline time of the restart is not counted if it is longer than tolerance
+	if err := extendSegment(ctx2, tx, id, "", dto.Login, dto.StartDateTime, dto.EndDateTime, 0, 0); err != nil {
+		return err
+	}
+
+	if err := insertHeartbeat(ctx2, tx, &domain.Heartbeat{
+		SessionID:      id,
+		Type:           domain.HeartbeatResume,
+		Login:          dto.Login,
+		ComputerName:   dto.ComputerName,
+		IPAddress:      dto.IPAddress,
+		NextPing:       dto.NextPing,
+		DateTime:       dto.StartDateTime,
+		ClientDateTime: dto.ClientDateTime,
+		ServerDateTime: dto.ServerDateTime,
+	}); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+func scanSessions(rows pgx.Rows) ([]response.Session, error) {
 	sessions := make([]response.Session, 0, 250)
 
 	for rows.Next() {
@@ -442,21 +933,39 @@ func (s *storage) IsSessionExists(ctx context.Context, login string) ([]response
 		); err != nil {
 			return nil, fmt.Errorf("in iterate row: %w", err)
 		}
+		session.StartDateTime = session.StartDateTime.UTC()
+		session.EndDateTime = session.EndDateTime.UTC()
 		sessions = append(sessions, session)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows at all: %w", err)
 	}
 
 	return sessions, nil
 }
 
+func insertAudit(ctx context.Context, tx pgx.Tx, admin, action, target, comment string) error {
+	if _, err := tx.Exec(ctx, `INSERT INTO session.audit_log (admin, action, target, comment)
+		VALUES ($1, $2, $3, $4);`,
+		admin,
+		action,
+		target,
+		comment,
+	); err != nil {
+		return customErr("audit: exec: insert", err)
+	}
+	return nil
+}
+
 func customErr(message string, err error) error {
 	if pgErr, ok := err.(*pgconn.PgError); ok {
 		if pgErr.Code == pgerrcode.UniqueViolation {
 			return &response.ErrDuplicateKey
 		}
+		if pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return &response.ErrForeignKey
+		}
 		if pgErr.Code == pgerrcode.RaiseException {
 			if pgErr.Message == response.ErrEndStartDate.Error() {
 				return &response.ErrEndStartDate
@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SyncUsers reconciles users with the directory: creates new logins, updates status
+// and flags users of the directory not found in it anymore as removed (deleted users are not changed),
+// users never found in the directory (created by hand) are not removed.
+func (s *storage) SyncUsers(ctx context.Context, dto *domain.UserSync) (*response.UserSync, error) {
+	ctx2, cancel := context.WithTimeout(ctx, 180*time.Second)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			log.Printf("SyncUsers: rollback: %s", err.Error())
+		}
+	}()
+
+	// one sync at a time
+	if _, err := tx.Exec(ctx2, `SELECT pg_advisory_xact_lock(hashtext('session_manager.user_sync'));`); err != nil {
+		return nil, fmt.Errorf("lock: %w", err)
+	}
+
+	logins := make([]string, 0, len(dto.Users))
+	statuses := make([]string, 0, len(dto.Users))
+	for _, user := range dto.Users {
+		logins = append(logins, user.Login)
+		statuses = append(statuses, user.Status)
+	}
+
+	report := response.UserSync{
+		Source:    dto.Source,
+		StartedAt: dto.StartedAt,
+		Total:     len(dto.Users),
+		Invalid:   dto.Invalid,
+	}
+
+	if report.Created, err = queryLogins(ctx2, tx, `INSERT INTO public.users (login, status)
+		SELECT d.login, COALESCE(NULLIF(d.status, ''), $3)
+		FROM unnest($1::text[], $2::text[]) AS d (login, status)
+		ON CONFLICT (login) DO NOTHING
+		RETURNING login;`,
+		logins,
+		statuses,
+		request.UserStatusActive,
+	); err != nil {
+		return nil, fmt.Errorf("create: %w", err)
+	}
+
+	if report.Updated, err = queryLogins(ctx2, tx, `UPDATE public.users u
+		SET status = d.status
+		FROM unnest($1::text[], $2::text[]) AS d (login, status)
+		WHERE u.login = d.login
+			AND d.status <> ''
+			AND u.status IS DISTINCT FROM d.status
+			AND u.deleted_at IS NULL
+		RETURNING u.login;`,
+		logins,
+		statuses,
+	); err != nil {
+		return nil, fmt.Errorf("update: %w", err)
+	}
+
+	if report.Restored, err = queryLogins(ctx2, tx, `UPDATE public.users
+		SET removed_at = NULL
+		WHERE removed_at IS NOT NULL AND login = ANY($1)
+		RETURNING login;`,
+		logins,
+	); err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx2, `UPDATE public.users
+		SET synced_at = NOW()
+		WHERE login = ANY($1);`,
+		logins,
+	); err != nil {
+		return nil, customErr("exec: synced", err)
+	}
+
+	if report.Removed, err = queryLogins(ctx2, tx, `UPDATE public.users
+		SET removed_at = NOW()
+		WHERE removed_at IS NULL AND deleted_at IS NULL AND synced_at IS NOT NULL AND login <> ALL($1)
+		RETURNING login;`,
+		logins,
+	); err != nil {
+		return nil, fmt.Errorf("remove: %w", err)
+	}
+
+	if err := tx.QueryRow(ctx2, `INSERT INTO
+		session.user_syncs (source, started_at, total, invalid, created, updated, removed, restored)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, finished_at;`,
+		report.Source,
+		report.StartedAt,
+		report.Total,
+		report.Invalid,
+		report.Created,
+		report.Updated,
+		report.Removed,
+		report.Restored,
+	).Scan(&report.ID, &report.FinishedAt); err != nil {
+		return nil, customErr("exec", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return &report, nil
+}
+
+// SaveUserSyncError saves the report of the failed sync.
+func (s *storage) SaveUserSyncError(ctx context.Context, dto *domain.UserSync, syncErr error) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, `INSERT INTO
+		session.user_syncs (source, started_at, total, invalid, error)
+		VALUES ($1, $2, $3, $4, $5);`,
+		dto.Source,
+		dto.StartedAt,
+		len(dto.Users),
+		dto.Invalid,
+		syncErr.Error(),
+	); err != nil {
+		return customErr("exec", err)
+	}
+
+	return nil
+}
+
+// GetUserSyncs returns the last reports.
+func (s *storage) GetUserSyncs(ctx context.Context) ([]response.UserSync, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, source, started_at, finished_at, total, invalid,
+			created, updated, removed, restored, COALESCE(error, '')
+		FROM session.user_syncs
+		ORDER BY id DESC
+		LIMIT 50;`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]response.UserSync, 0, 50)
+
+	for rows.Next() {
+		report := response.UserSync{}
+		if err := rows.Scan(
+			&report.ID,
+			&report.Source,
+			&report.StartedAt,
+			&report.FinishedAt,
+			&report.Total,
+			&report.Invalid,
+			&report.Created,
+			&report.Updated,
+			&report.Removed,
+			&report.Restored,
+			&report.Error,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return reports, nil
+}
+
+// queryLogins returns sorted logins returned by the query
+func queryLogins(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, customErr("query", err)
+	}
+	defer rows.Close()
+
+	logins := make([]string, 0, 10)
+
+	for rows.Next() {
+		var login string
+		if err := rows.Scan(&login); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		logins = append(logins, login)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	sort.Strings(logins)
+
+	return logins, nil
+}
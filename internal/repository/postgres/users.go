@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const usersFilter = `($1 = '' OR login LIKE $1)
+	AND ($2 = '' OR status = $2)
+	AND ($3 OR deleted_at IS NULL)`
+
+func (s *storage) GetUsers(ctx context.Context, dto *domain.UserFilter) (*response.Users, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	pattern := ""
+	if dto.Prefix != "" {
+		pattern = escapeLike(dto.Prefix) + "%"
+	}
+
+	users := response.Users{
+		Users: make([]response.User, 0, dto.Limit),
+	}
+
+	if err := s.pool.QueryRow(ctx,
+		`SELECT COUNT(*)
+		FROM public.users
+		WHERE `+usersFilter+`;`,
+		pattern,
+		dto.Status,
+		dto.Deleted,
+	).Scan(&users.Total); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT login, COALESCE(status, ''), deleted_at, removed_at
+		FROM public.users
+		WHERE `+usersFilter+`
+		ORDER BY login
+		LIMIT $4 OFFSET $5;`,
+		pattern,
+		dto.Status,
+		dto.Deleted,
+		dto.Limit,
+		dto.Offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user := response.User{}
+		if err := rows.Scan(
+			&user.Login,
+			&user.Status,
+			&user.DeletedAt,
+			&user.RemovedAt,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		users.Users = append(users.Users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return &users, nil
+}
+
+func (s *storage) GetUser(ctx context.Context, login string) (*response.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	user := response.User{}
+
+	if err := s.pool.QueryRow(ctx,
+		`SELECT login, COALESCE(status, ''), deleted_at, removed_at
+		FROM public.users
+		WHERE login = $1;`,
+		login,
+	).Scan(
+		&user.Login,
+		&user.Status,
+		&user.DeletedAt,
+		&user.RemovedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &response.ErrNotFound
+		}
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	return &user, nil
+}
+
+func (s *storage) UpdateUser(ctx context.Context, dto *domain.UserUpdate) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if tag, err := s.pool.Exec(ctx, `UPDATE public.users
+		SET status = $1
+		WHERE login = $2 AND deleted_at IS NULL;`,
+		dto.Status,
+		dto.Login,
+	); err != nil {
+		return customErr("exec", err)
+	} else if tag.RowsAffected() == 0 {
+		return &response.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteUser marks the user as deleted, sessions history is kept.
+func (s *storage) DeleteUser(ctx context.Context, login string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if tag, err := s.pool.Exec(ctx, `UPDATE public.users
+		SET deleted_at = NOW()
+		WHERE login = $1 AND deleted_at IS NULL;`,
+		login,
+	); err != nil {
+		return customErr("exec", err)
+	} else if tag.RowsAffected() == 0 {
+		return &response.ErrNotFound
+	}
+
+	return nil
+}
+
+// escapeLike escapes LIKE wildcards of user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+	"time"
+)
+
+// GetZones returns zones in the order their patterns are matched.
+func (s *storage) GetZones(ctx context.Context) ([]response.Zone, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT name, COALESCE(comp_pattern, ''), priority
+		FROM session.zones
+		ORDER BY priority DESC, name;`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	defer rows.Close()
+
+	zones := make([]response.Zone, 0, 10)
+
+	for rows.Next() {
+		zone := response.Zone{}
+		if err := rows.Scan(
+			&zone.Name,
+			&zone.ComputerPattern,
+			&zone.Priority,
+		); err != nil {
+			return nil, fmt.Errorf("in iterate row: %w", err)
+		}
+		zones = append(zones, zone)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows at all: %w", err)
+	}
+
+	return zones, nil
+}
+
+func (s *storage) SetZone(ctx context.Context, dto *domain.Zone) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, `INSERT INTO
+		session.zones (name, comp_pattern, priority)
+		VALUES ($1, NULLIF($2, ''), $3)
+		ON CONFLICT (name)
+		DO UPDATE SET
+		comp_pattern = EXCLUDED.comp_pattern,
+		priority = EXCLUDED.priority;`,
+		dto.Name,
+		dto.ComputerPattern,
+		dto.Priority,
+	); err != nil {
+		return customErr("exec", err)
+	}
+
+	return nil
+}
+
+func (s *storage) DeleteZone(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if tag, err := s.pool.Exec(ctx, `DELETE FROM session.zones
+		WHERE name = $1;`,
+		name,
+	); err != nil {
+		return customErr("exec", err)
+	} else if tag.RowsAffected() == 0 {
+		return &response.ErrNotFound
+	}
+
+	return nil
+}
@@ -3,7 +3,12 @@ package server
 import (
 	"context"
 	"log"
+	"os"
+	"session_manager/internal/directory"
+	"session_manager/internal/domain/request"
 	"session_manager/internal/repository/postgres"
+	"session_manager/internal/service"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,14 +16,18 @@ import (
 
 type Env struct {
 	pool *pgxpool.Pool
+	cfg  service.Config
 }
 
 func NewEnv(ctx context.Context) *Env {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
+	cfg := newServiceConfig()
+
 	return &Env{
-		pool: postgres.NewPostgres(ctx),
+		pool: postgres.NewPostgres(ctx, cfg.Location.String()),
+		cfg:  cfg,
 	}
 }
 
@@ -29,3 +38,60 @@ func (e *Env) Stop(ctx context.Context) {
 	e.pool.Close()
 	log.Println("envorinments stopped")
 }
+
+func newServiceConfig() service.Config {
+	cfg := service.Config{
+		ComputerPolicy: getEnv("SESSION_COMPUTER_POLICY", service.ComputerPolicyReject),
+		ResumeGrace:    time.Duration(getEnvInt("SESSION_RESUME_GRACE_SEC", 300)) * time.Second,
+		ClockPolicy:    getEnv("CLOCK_SKEW_POLICY", service.ClockPolicyFlag),
+		ClockSkewMax:   time.Duration(getEnvInt("CLOCK_SKEW_MAX_SEC", 120)) * time.Second,
+
+		UserSyncInterval: time.Duration(getEnvInt("USER_SYNC_INTERVAL_SEC", 0)) * time.Second,
+	}
+
+	if pattern := getEnv("COMPUTER_REGISTER_PATTERN", ""); pattern != "" {
+		re, err := request.CompileNamePattern(pattern)
+		if err != nil {
+			log.Fatalf("[config] COMPUTER_REGISTER_PATTERN: %s", err)
+		}
+		cfg.ComputerRegisterPattern = re
+	}
+
+	loc, err := time.LoadLocation(getEnv("CAMPUS_TIMEZONE", "Asia/Almaty"))
+	if err != nil {
+		log.Fatalf("[config] CAMPUS_TIMEZONE: %s", err)
+	}
+	cfg.Location = loc
+
+	if source := getEnv("USER_SYNC_SOURCE", ""); source != "" {
+		cfg.UserSource = directory.NewSource(source,
+			getEnv("USER_SYNC_LOGIN_ATTR", "uid"),
+			getEnv("USER_SYNC_STATUS_ATTR", ""),
+		)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("[config] %s", err)
+	}
+
+	return cfg
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("[config] %s: %s", key, err)
+	}
+	return n
+}
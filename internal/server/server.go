@@ -22,6 +22,7 @@ type Server interface {
 
 type server struct {
 	router *echo.Echo
+	svc    service.Service
 }
 
 func NewServer(env *Env) Server {
@@ -33,10 +34,11 @@ func NewServer(env *Env) Server {
 	storage := postgres.NewStorage(env.pool)
 
 	// service
-	svc := service.New(storage)
+	svc := service.New(storage, env.cfg)
+	s.svc = svc
 
 	// handlers
-	hndl := api.NewHandlers(s.router.Logger, svc)
+	hndl := api.NewHandlers(s.router.Logger, svc, env.cfg.Location)
 
 	// set middlewares
 	s.router.Use(middleware.Logger(), middleware.Recover())
@@ -44,11 +46,42 @@ func NewServer(env *Env) Server {
 	// register handlers
 	g := s.router.Group("/api/session-manager")
 	g.POST("/users", hndl.CreateUsers)
+	g.GET("/users", hndl.GetUsers)
+	g.GET("/users/:login", hndl.GetUser)
+	g.PATCH("/users/:login", hndl.UpdateUser)
+	g.DELETE("/users/:login", hndl.DeleteUser)
+	g.POST("/admin/users/sync", hndl.SyncUsers)
+	g.GET("/admin/users/sync", hndl.GetUserSyncs)
 	g.POST("/computers", hndl.CreateComputers)
+	g.GET("/computers", hndl.GetComputers)
+	g.GET("/computers/clock-skew", hndl.GetClockSkews)
+	g.GET("/computers/:name", hndl.GetComputer)
+	g.PATCH("/computers/:name", hndl.UpdateComputer)
+	g.DELETE("/computers/:name", hndl.RetireComputer)
 	g.POST("/session", hndl.CreateSession)
 	g.POST("/activity", hndl.CreateActivity)
+	g.POST("/session/:id/end", hndl.EndSession)
+	g.POST("/admin/session/terminate", hndl.TerminateSessions)
+	g.POST("/admin/commands", hndl.CreateCommand)
+	g.GET("/admin/commands", hndl.GetCommands)
+	g.POST("/admin/rebuild", hndl.RebuildSessions)
+	g.GET("/policies", hndl.GetSessionPolicies)
+	g.PUT("/policies/:status", hndl.SetSessionPolicy)
 	g.GET("/dashboard", hndl.GetOnlineSessions)
+	g.GET("/dashboard/zones", hndl.GetZoneDashboard)
+	g.GET("/floor-map", hndl.GetFloorMap)
+	g.GET("/zones", hndl.GetZones)
+	g.PUT("/zones/:name", hndl.SetZone)
+	g.DELETE("/zones/:name", hndl.DeleteZone)
 	g.GET("/activity", hndl.GetUserActivity)
+	g.GET("/leaderboard", hndl.GetLeaderboard)
+	g.POST("/cohorts", hndl.CreateCohort)
+	g.GET("/cohorts", hndl.GetCohorts)
+	g.GET("/cohorts/:name", hndl.GetCohort)
+	g.DELETE("/cohorts/:name", hndl.DeleteCohort)
+	g.POST("/cohorts/:name/members", hndl.AddCohortMembers)
+	g.DELETE("/cohorts/:name/members", hndl.RemoveCohortMembers)
+	g.GET("/cohorts/:name/activity", hndl.GetCohortActivity)
 
 	return &s
 }
@@ -64,6 +97,9 @@ func (s *server) Run(ctx context.Context) {
 		}
 	}()
 
+	// background jobs
+	go s.svc.RunUserSync(ctxSignal)
+
 	// wait system notifiers or cancel func
 	<-ctxSignal.Done()
 }
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/response"
+)
+
+func (s *service) CreateCohort(ctx context.Context, dto *domain.Cohort) error {
+	return s.storage.CreateCohort(ctx, dto)
+}
+
+func (s *service) GetCohorts(ctx context.Context) ([]response.Cohort, error) {
+	return s.storage.GetCohorts(ctx)
+}
+
+func (s *service) GetCohort(ctx context.Context, name string) (*response.Cohort, error) {
+	return s.storage.GetCohort(ctx, name)
+}
+
+func (s *service) DeleteCohort(ctx context.Context, name string) error {
+	return s.storage.DeleteCohort(ctx, name)
+}
+
+func (s *service) AddCohortMembers(ctx context.Context, dto *domain.CohortMembers) (*response.CohortMembers, error) {
+	return s.storage.AddCohortMembers(ctx, dto)
+}
+
+func (s *service) RemoveCohortMembers(ctx context.Context, dto *domain.CohortMembers) (*response.CohortMembers, error) {
+	return s.storage.RemoveCohortMembers(ctx, dto)
+}
+
+// GetCohortActivity returns activity of every member of the cohort,
+// members without hours in the range are shown with zero hours.
+func (s *service) GetCohortActivity(ctx context.Context, dto *domain.CohortActivity) (*response.CohortActivity, error) {
+	cohort, err := s.storage.GetCohort(ctx, dto.Cohort)
+	if err != nil {
+		return nil, fmt.Errorf("GetCohort: %w", err)
+	}
+
+	report := response.CohortActivity{
+		Cohort:  cohort.Name,
+		Members: cohort.Members,
+		Users:   make([]response.UserActivity, 0, cohort.Members),
+	}
+	if cohort.Members == 0 {
+		return &report, nil
+	}
+
+	dto.Logins = cohort.Logins
+	users, err := s.getActivity(ctx, &dto.UserActivity)
+	if err != nil {
+		return nil, err
+	}
+
+	// both are ordered by login
+	i := 0
+	for _, login := range cohort.Logins {
+		if i < len(users) && users[i].Login == login {
+			report.Users = append(report.Users, users[i])
+			i++
+		} else {
+			report.Users = append(report.Users, response.UserActivity{
+				Login:        login,
+				UserActivity: []struct{}{}, // to show empty array
+			})
+		}
+	}
+
+	var totalHours, totalActiveHours, totalIdleHours float64
+	for _, user := range report.Users {
+		totalHours += float64(user.TotalHours)
+		totalActiveHours += float64(user.TotalActiveHours)
+		totalIdleHours += float64(user.TotalIdleHours)
+	}
+
+	members := float64(report.Members)
+	report.TotalHours = round(totalHours)
+	report.TotalActiveHours = round(totalActiveHours)
+	report.TotalIdleHours = round(totalIdleHours)
+	report.AverageHours = round(totalHours / members)
+	report.AverageActiveHours = round(totalActiveHours / members)
+
+	return &report, nil
+}
+
+func round(hours float64) float32 {
+	return float32(math.Round(hours*100) / 100)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"session_manager/internal/directory"
+	"time"
+)
+
+// what to do when a computer already has an active session of another login
+const (
+	ComputerPolicyReject = "reject" // new session is denied
+	ComputerPolicyClose  = "close"  // previous session is closed
+	ComputerPolicyAllow  = "allow"  // sessions may overlap
+)
+
+// what to do when the computer clock differs from the server more than allowed
+const (
+	ClockPolicyFlag    = "flag"    // accept the computer time and flag the session
+	ClockPolicyCorrect = "correct" // use the server time and flag the session
+	ClockPolicyReject  = "reject"  // deny the request
+)
+
+type Config struct {
+	ComputerPolicy string
+	// new session of the same login on the same computer resumes the previous one
+	// if it is still alive or ended less than ResumeGrace ago (agent restart), 0 - disabled
+	ResumeGrace time.Duration
+
+	ClockPolicy  string
+	ClockSkewMax time.Duration // 0 - skew is not checked
+
+	// unknown computers matching the whole name are registered by the first session, nil - disabled
+	ComputerRegisterPattern *regexp.Regexp
+
+	UserSource       directory.UserSource // nil - sync is disabled
+	UserSyncInterval time.Duration        // 0 - only by request
+
+	// timezone of the campus: reports without tz are computed in it
+	Location *time.Location
+}
+
+func (c *Config) Validate() error {
+	switch c.ComputerPolicy {
+	case ComputerPolicyReject, ComputerPolicyClose, ComputerPolicyAllow:
+	default:
+		return fmt.Errorf("computer policy must be '%s', '%s' or '%s'",
+			ComputerPolicyReject, ComputerPolicyClose, ComputerPolicyAllow)
+	}
+	if c.ResumeGrace < 0 {
+		return errors.New("resume grace less than 0")
+	}
+	switch c.ClockPolicy {
+	case ClockPolicyFlag, ClockPolicyCorrect, ClockPolicyReject:
+	default:
+		return fmt.Errorf("clock policy must be '%s', '%s' or '%s'",
+			ClockPolicyFlag, ClockPolicyCorrect, ClockPolicyReject)
+	}
+	if c.ClockSkewMax < 0 {
+		return errors.New("clock skew max less than 0")
+	}
+	if c.UserSyncInterval < 0 {
+		return errors.New("user sync interval less than 0")
+	}
+	if c.Location == nil {
+		return errors.New("campus timezone is empty")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+)
+
+// ImportUsers streams users from csv to the storage.
+func (s *service) ImportUsers(ctx context.Context, dto *domain.Import, reader *request.CSVReader) (*response.ImportResults, error) {
+	return s.storage.ImportUsers(ctx, dto, func() (*domain.UserImport, error) {
+		user, err := reader.NextUser()
+		return user, csvErr(err)
+	})
+}
+
+// ImportComputers streams computers from csv to the storage.
+func (s *service) ImportComputers(ctx context.Context, dto *domain.Import, reader *request.CSVReader) (*response.ImportResults, error) {
+	return s.storage.ImportComputers(ctx, dto, func() (*domain.ComputerImport, error) {
+		computer, err := reader.NextComputer()
+		return computer, csvErr(err)
+	})
+}
+
+// csvErr makes broken csv a bad request, io.EOF is returned as is
+func csvErr(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return err
+	}
+	return &response.ErrBadReq{Message: fmt.Sprintf("csv: %s", err)}
+}
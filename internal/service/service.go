@@ -2,52 +2,490 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"session_manager/internal/domain"
 	"session_manager/internal/domain/request"
 	"session_manager/internal/domain/response"
 	"session_manager/internal/repository/postgres"
+	"sync"
+	"time"
 )
 
 type Service interface {
-	CreateUsers(ctx context.Context, req []request.User) error
-	CreateComputers(ctx context.Context, req []request.Computer) error
-	CreateSession(ctx context.Context, dto *domain.Session) ([]response.Session, error)
-	CreateActivity(ctx context.Context, dto *domain.Activity) error
+	CreateUsers(ctx context.Context, req []request.User) (*response.CreateResults, error)
+	CreateComputers(ctx context.Context, req []request.Computer) (*response.CreateResults, error)
+	ImportUsers(ctx context.Context, dto *domain.Import, reader *request.CSVReader) (*response.ImportResults, error)
+	ImportComputers(ctx context.Context, dto *domain.Import, reader *request.CSVReader) (*response.ImportResults, error)
+	GetUsers(ctx context.Context, dto *domain.UserFilter) (*response.Users, error)
+	GetUser(ctx context.Context, login string) (*response.User, error)
+	UpdateUser(ctx context.Context, dto *domain.UserUpdate) error
+	DeleteUser(ctx context.Context, login string) error
+	RunUserSync(ctx context.Context)
+	SyncUsers(ctx context.Context) (*response.UserSync, error)
+	GetUserSyncs(ctx context.Context) ([]response.UserSync, error)
+	GetComputers(ctx context.Context, dto *domain.ComputerFilter) (*response.Computers, error)
+	GetComputer(ctx context.Context, compName string) (*response.Computer, error)
+	UpdateComputer(ctx context.Context, dto *domain.ComputerUpdate) error
+	RetireComputer(ctx context.Context, compName string) error
+	GetFloorMap(ctx context.Context, dto *domain.FloorFilter) (*response.FloorMap, error)
+	CreateSession(ctx context.Context, dto *domain.Session) (*response.SessionStart, []response.Session, error)
+	CreateActivity(ctx context.Context, dto *domain.Activity) (*response.Heartbeat, error)
+	EndSession(ctx context.Context, dto *domain.SessionEnd) error
+	TerminateSessions(ctx context.Context, dto *domain.SessionTerminate) ([]response.Session, error)
+	CreateCommand(ctx context.Context, dto *domain.Command) (int64, error)
+	GetSessionPolicies(ctx context.Context) ([]response.SessionPolicy, error)
+	SetSessionPolicy(ctx context.Context, dto *domain.SessionPolicy) error
+	GetZones(ctx context.Context) ([]response.Zone, error)
+	SetZone(ctx context.Context, dto *domain.Zone) error
+	DeleteZone(ctx context.Context, name string) error
+	GetZoneDashboard(ctx context.Context) ([]response.ZoneOccupancy, error)
+	GetClockSkews(ctx context.Context, dto *domain.ClockSkewFilter) ([]response.ComputerClock, error)
+	RebuildSessions(ctx context.Context, dto *domain.Rebuild) (*response.Rebuild, error)
+	GetCommands(ctx context.Context, dto *domain.CommandFilter) ([]response.CommandInfo, error)
 	GetOnlineDashboard(ctx context.Context) ([]response.Session, error)
 	GetUserActivity(ctx context.Context, dto *domain.UserActivity) (activity *response.UserActivity, err error)
+	GetLeaderboard(ctx context.Context, dto *domain.Leaderboard) (*response.Leaderboard, error)
+	CreateCohort(ctx context.Context, dto *domain.Cohort) error
+	GetCohorts(ctx context.Context) ([]response.Cohort, error)
+	GetCohort(ctx context.Context, name string) (*response.Cohort, error)
+	DeleteCohort(ctx context.Context, name string) error
+	AddCohortMembers(ctx context.Context, dto *domain.CohortMembers) (*response.CohortMembers, error)
+	RemoveCohortMembers(ctx context.Context, dto *domain.CohortMembers) (*response.CohortMembers, error)
+	GetCohortActivity(ctx context.Context, dto *domain.CohortActivity) (*response.CohortActivity, error)
 }
 
-func New(storage postgres.Storage) Service {
-	return &service{storage: storage}
+func New(storage postgres.Storage, cfg Config) Service {
+	return &service{
+		storage: storage,
+		cfg:     cfg,
+	}
 }
 
 type service struct {
 	storage postgres.Storage
+	cfg     Config
+
+	namePatterns sync.Map // comp_pattern of policies and zones -> *regexp.Regexp
+}
+
+// CreateUsers returns the result for every user in the order of request,
+// invalid users are not sent to the storage.
+func (s *service) CreateUsers(ctx context.Context, req []request.User) (*response.CreateResults, error) {
+	results := make([]response.CreateResult, len(req))
+	valid := make([]request.User, 0, len(req))
+
+	for i := range req {
+		if err := req[i].Validate(); err != nil {
+			results[i] = invalidResult(req[i].Name, err)
+			continue
+		}
+		valid = append(valid, req[i])
+	}
+
+	created, err := s.storage.CreateUsers(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCreateResults(results, created), nil
+}
+
+// CreateComputers returns the result for every computer in the order of request,
+// invalid computers are not sent to the storage.
+func (s *service) CreateComputers(ctx context.Context, req []request.Computer) (*response.CreateResults, error) {
+	results := make([]response.CreateResult, len(req))
+	valid := make([]request.Computer, 0, len(req))
+
+	for i := range req {
+		if err := req[i].Validate(); err != nil {
+			results[i] = invalidResult(req[i].Name, err)
+			continue
+		}
+		valid = append(valid, req[i])
+	}
+
+	created, err := s.storage.CreateComputers(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCreateResults(results, created), nil
+}
+
+func invalidResult(name string, err error) response.CreateResult {
+	return response.CreateResult{
+		Name:   name,
+		Result: response.ResultInvalid,
+		Reason: err.Error(),
+	}
+}
+
+// newCreateResults fills the gaps between invalid results with results of the storage
+func newCreateResults(results, created []response.CreateResult) *response.CreateResults {
+	createResults := response.CreateResults{Results: results}
+
+	for i := range results {
+		if results[i].Result == "" && len(created) != 0 {
+			results[i], created = created[0], created[1:]
+		}
+		switch results[i].Result {
+		case response.ResultCreated:
+			createResults.Created++
+		case response.ResultExists:
+			createResults.Exists++
+		case response.ResultInvalid:
+			createResults.Invalid++
+		}
+	}
+
+	return &createResults
+}
+
+func (s *service) GetUsers(ctx context.Context, dto *domain.UserFilter) (*response.Users, error) {
+	return s.storage.GetUsers(ctx, dto)
+}
+
+func (s *service) GetUser(ctx context.Context, login string) (*response.User, error) {
+	return s.storage.GetUser(ctx, login)
+}
+
+func (s *service) UpdateUser(ctx context.Context, dto *domain.UserUpdate) error {
+	return s.storage.UpdateUser(ctx, dto)
+}
+
+func (s *service) DeleteUser(ctx context.Context, login string) error {
+	return s.storage.DeleteUser(ctx, login)
+}
+
+func (s *service) GetComputers(ctx context.Context, dto *domain.ComputerFilter) (*response.Computers, error) {
+	return s.storage.GetComputers(ctx, dto)
+}
+
+func (s *service) GetComputer(ctx context.Context, compName string) (*response.Computer, error) {
+	return s.storage.GetComputer(ctx, compName)
+}
+
+func (s *service) UpdateComputer(ctx context.Context, dto *domain.ComputerUpdate) error {
+	return s.storage.UpdateComputer(ctx, dto)
+}
+
+func (s *service) RetireComputer(ctx context.Context, compName string) error {
+	return s.storage.RetireComputer(ctx, compName)
+}
+
+// GetFloorMap joins computers of the floor with online sessions to show which seats are taken.
+func (s *service) GetFloorMap(ctx context.Context, dto *domain.FloorFilter) (*response.FloorMap, error) {
+	computers, err := s.storage.GetFloorComputers(ctx, dto)
+	if err != nil {
+		return nil, fmt.Errorf("GetFloorComputers: %w", err)
+	}
+
+	sessions, err := s.GetOnlineDashboard(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetOnlineDashboard: %w", err)
+	}
+
+	online := make(map[string]*response.Session, len(sessions))
+	for i := range sessions {
+		online[sessions[i].ComputerName] = &sessions[i]
+	}
+
+	floorMap := response.FloorMap{
+		Total: len(computers),
+		Seats: make([]response.FloorSeat, 0, len(computers)),
+	}
+	for _, computer := range computers {
+		seat := response.FloorSeat{
+			Computer: computer,
+			Session:  online[computer.ComputerName],
+		}
+		if seat.Session != nil {
+			floorMap.Taken++
+		}
+		floorMap.Seats = append(floorMap.Seats, seat)
+	}
+
+	return &floorMap, nil
+}
+
+func (s *service) CreateSession(ctx context.Context, dto *domain.Session) (*response.SessionStart, []response.Session, error) {
+	if err := s.checkUser(ctx, dto.Login); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.checkComputerStatus(ctx, dto.ComputerName); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.checkClock(ctx, dto.ComputerName, "", &dto.Clock, &dto.StartDateTime, &dto.EndDateTime); err != nil {
+		return nil, nil, err
+	}
+
+	// agent restarted on the same computer: continue its previous session
+	if s.cfg.ResumeGrace > 0 {
+		prev, err := s.storage.GetResumableSession(ctx, dto.Login, dto.ComputerName, s.cfg.ResumeGrace)
+		if err != nil {
+			return nil, nil, fmt.Errorf("GetResumableSession: %w", err)
+		}
+		if prev != nil {
+			if err := s.storage.ResumeSession(ctx, prev.ID, dto); err != nil {
+				return nil, nil, fmt.Errorf("ResumeSession: %w", err)
+			}
+			return &response.SessionStart{ID: prev.ID, Resumed: true}, nil, nil
+		}
+	}
+
+	// first check if session is allowed by the user's status policy
+	if sessions, err := s.checkPolicy(ctx, dto); err != nil {
+		return nil, sessions, err
+	}
+
+	// then check if computer is used by another login
+	if sessions, err := s.checkComputer(ctx, dto); err != nil {
+		return nil, sessions, err
+	}
+
+	// create session
+	if err := s.storage.CreateSession(ctx, dto); err != nil {
+		return nil, nil, err
+	}
+
+	return &response.SessionStart{ID: dto.ID}, nil, nil
+}
+
+// messages for users who may not start a session, shown on the login screen
+var userDeniedMessages = map[string]string{
+	request.UserStatusBlocked:   "Your account is blocked. Please contact the administration.",
+	request.UserStatusExpelled:  "Your account is closed. Please contact the administration.",
+	request.UserStatusGraduated: "You have graduated, campus access is closed. Please contact the administration.",
+}
+
+func (s *service) checkUser(ctx context.Context, login string) error {
+	user, err := s.storage.GetUser(ctx, login)
+	if err != nil {
+		if errors.Is(err, &response.ErrNotFound) {
+			return &response.ErrForbidden{
+				Reason:  "unknown",
+				Message: "Your login is not registered. Please contact the administration.",
+			}
+		}
+		return fmt.Errorf("GetUser: %w", err)
+	}
+
+	if user.DeletedAt != nil {
+		return &response.ErrForbidden{
+			Reason:  "deleted",
+			Message: "Your account is deleted. Please contact the administration.",
+		}
+	}
+	if message, ok := userDeniedMessages[user.Status]; ok {
+		return &response.ErrForbidden{
+			Reason:  user.Status,
+			Message: message,
+		}
+	}
+
+	return nil
+}
+
+// messages for computers that may not be used, shown on the login screen
+var computerDeniedMessages = map[string]string{
+	request.ComputerStatusMaintenance: "This computer is under maintenance. Please use another one.",
+	request.ComputerStatusRetired:     "This computer is out of service. Please use another one.",
+}
+
+// registerComputer registers the unknown computer as unverified if its name matches the pattern.
+func (s *service) registerComputer(ctx context.Context, compName string) error {
+	if s.cfg.ComputerRegisterPattern != nil {
+		if s.cfg.ComputerRegisterPattern.MatchString(compName) && (&request.Computer{Name: compName}).Validate() == nil {
+			if err := s.storage.RegisterComputer(ctx, compName); err != nil {
+				return fmt.Errorf("RegisterComputer: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return &response.ErrForbidden{
+		Reason:  "unregistered",
+		Message: "This computer is not registered. Please contact the administration.",
+	}
 }
 
-func (s *service) CreateUsers(ctx context.Context, req []request.User) error {
-	return s.storage.CreateUsers(ctx, req)
+func (s *service) checkComputerStatus(ctx context.Context, compName string) error {
+	computer, err := s.storage.GetComputer(ctx, compName)
+	if err != nil {
+		if errors.Is(err, &response.ErrNotFound) {
+			return s.registerComputer(ctx, compName)
+		}
+		return fmt.Errorf("GetComputer: %w", err)
+	}
+
+	if message, ok := computerDeniedMessages[computer.Status]; ok {
+		return &response.ErrForbidden{
+			Reason:  computer.Status,
+			Message: message,
+		}
+	}
+
+	return nil
 }
 
-func (s *service) CreateComputers(ctx context.Context, req []request.Computer) error {
-	return s.storage.CreateComputers(ctx, req)
+// checkClock saves the computer clock skew and applies the clock policy if skew exceeds the threshold:
+// start and end are moved to the server time on correct policy.
+func (s *service) checkClock(ctx context.Context, compName, sessionID string, clock *domain.Clock, start, end *time.Time) error {
+	skew := clock.Skew()
+
+	if err := s.storage.SetClockSkew(ctx, compName, sessionID, skew); err != nil {
+		return fmt.Errorf("SetClockSkew: %w", err)
+	}
+
+	if s.cfg.ClockSkewMax == 0 || skew.Abs() <= s.cfg.ClockSkewMax {
+		return nil
+	}
+
+	switch s.cfg.ClockPolicy {
+	case ClockPolicyReject:
+		return &response.ErrBadReq{Message: fmt.Sprintf("computer clock differs from server by %s, allowed %s",
+			skew.Round(time.Second), s.cfg.ClockSkewMax)}
+	case ClockPolicyCorrect:
+		*start = start.Add(-skew)
+		*end = end.Add(-skew)
+	}
+	clock.Skewed = true
+
+	return nil
 }
 
-func (s *service) CreateSession(ctx context.Context, dto *domain.Session) ([]response.Session, error) {
-	// first check if session already exists
-	if sessions, err := s.storage.IsSessionExists(ctx, dto.Login); err != nil {
+func (s *service) checkPolicy(ctx context.Context, dto *domain.Session) ([]response.Session, error) {
+	policy, err := s.storage.GetSessionPolicy(ctx, dto.Login)
+	if err != nil {
+		return nil, fmt.Errorf("GetSessionPolicy: %w", err)
+	}
+
+	if policy.ComputerPattern != "" {
+		re, err := s.namePattern(policy.ComputerPattern)
+		if err != nil {
+			return nil, fmt.Errorf("policy '%s': comp_pattern: %w", policy.Status, err)
+		}
+		if !re.MatchString(dto.ComputerName) {
+			return nil, response.ErrComputerDeny
+		}
+	}
+
+	sessions, err := s.storage.IsSessionExists(ctx, dto.Login)
+	if err != nil {
 		return nil, fmt.Errorf("IsSessionExists: %w", err)
-	} else if len(sessions) != 0 {
+	}
+	if policy.MaxSessions > 0 && len(sessions) >= policy.MaxSessions {
 		return sessions, response.ErrAccessDenied
 	}
 
-	// create session
-	return nil, s.storage.CreateSession(ctx, dto)
+	return nil, nil
+}
+
+// namePattern returns the compiled comp_pattern of a policy or a zone, patterns are compiled once
+func (s *service) namePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := s.namePatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := request.CompileNamePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.namePatterns.Store(pattern, re)
+	return re, nil
+}
+
+func (s *service) checkComputer(ctx context.Context, dto *domain.Session) ([]response.Session, error) {
+	if s.cfg.ComputerPolicy == ComputerPolicyAllow {
+		return nil, nil
+	}
+
+	sessions, err := s.storage.GetComputerSessions(ctx, dto.ComputerName)
+	if err != nil {
+		return nil, fmt.Errorf("GetComputerSessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+
+	if s.cfg.ComputerPolicy == ComputerPolicyReject {
+		return sessions, response.ErrComputerBusy
+	}
+
+	// agent missed the logout of previous user, close it at the start of new session
+	for _, session := range sessions {
+		if err := s.storage.EndSession(ctx, &domain.SessionEnd{
+			ID:          session.ID,
+			Reason:      request.EndReasonForced,
+			EndDateTime: dto.StartDateTime,
+		}); err != nil {
+			return nil, fmt.Errorf("EndSession: %w", err)
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *service) CreateActivity(ctx context.Context, dto *domain.Activity) (*response.Heartbeat, error) {
+	if err := s.checkClock(ctx, "", dto.SessionID, &dto.Clock, &dto.StartDateTime, &dto.EndDateTime); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.CreateActivity(ctx, dto); err != nil {
+		return nil, err
+	}
+
+	// commands executed by the computer since last ping
+	if len(dto.AckCommands) != 0 {
+		if err := s.storage.AckCommands(ctx, dto.SessionID, dto.AckCommands); err != nil {
+			return nil, fmt.Errorf("AckCommands: %w", err)
+		}
+	}
+
+	commands, err := s.storage.DeliverCommands(ctx, dto.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("DeliverCommands: %w", err)
+	}
+
+	return &response.Heartbeat{Commands: commands}, nil
+}
+
+func (s *service) EndSession(ctx context.Context, dto *domain.SessionEnd) error {
+	return s.storage.EndSession(ctx, dto)
+}
+
+func (s *service) TerminateSessions(ctx context.Context, dto *domain.SessionTerminate) ([]response.Session, error) {
+	return s.storage.TerminateSessions(ctx, dto)
+}
+
+func (s *service) CreateCommand(ctx context.Context, dto *domain.Command) (int64, error) {
+	return s.storage.CreateCommand(ctx, dto)
 }
 
-func (s *service) CreateActivity(ctx context.Context, dto *domain.Activity) error {
-	return s.storage.CreateActivity(ctx, dto)
+func (s *service) GetCommands(ctx context.Context, dto *domain.CommandFilter) ([]response.CommandInfo, error) {
+	return s.storage.GetCommands(ctx, dto)
+}
+
+func (s *service) GetSessionPolicies(ctx context.Context) ([]response.SessionPolicy, error) {
+	return s.storage.GetSessionPolicies(ctx)
+}
+
+func (s *service) SetSessionPolicy(ctx context.Context, dto *domain.SessionPolicy) error {
+	return s.storage.SetSessionPolicy(ctx, dto)
+}
+
+func (s *service) GetClockSkews(ctx context.Context, dto *domain.ClockSkewFilter) ([]response.ComputerClock, error) {
+	if dto.MinSkew == 0 {
+		dto.MinSkew = s.cfg.ClockSkewMax
+	}
+	return s.storage.GetClockSkews(ctx, dto)
+}
+
+func (s *service) RebuildSessions(ctx context.Context, dto *domain.Rebuild) (*response.Rebuild, error) {
+	return s.storage.RebuildSessions(ctx, dto)
 }
 
 func (s *service) GetOnlineDashboard(ctx context.Context) ([]response.Session, error) {
@@ -55,9 +493,60 @@ func (s *service) GetOnlineDashboard(ctx context.Context) ([]response.Session, e
 }
 
 func (s *service) GetUserActivity(ctx context.Context, dto *domain.UserActivity) (activity *response.UserActivity, err error) {
-	if dto.GroupBy == request.GroupByMonth {
+	users, err := s.getActivity(ctx, dto)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return &response.UserActivity{
+			Login:        dto.Login,
+			UserActivity: []struct{}{}, // to show empty array
+		}, nil
+	}
+
+	return &users[0], nil
+}
+
+func (s *service) GetLeaderboard(ctx context.Context, dto *domain.Leaderboard) (*response.Leaderboard, error) {
+	dto.Location, dto.FromDate, dto.ToDate = s.reportDays(dto.Location, dto.FromDate, dto.ToDate)
+	return s.storage.GetLeaderboard(ctx, dto)
+}
+
+// getActivity returns activity of users having hours in the range, ordered by login
+func (s *service) getActivity(ctx context.Context, dto *domain.UserActivity) ([]response.UserActivity, error) {
+	dto.Location, dto.FromDate, dto.ToDate = s.reportDays(dto.Location, dto.FromDate, dto.ToDate)
+
+	switch dto.GroupBy {
+	case request.GroupByMonth:
 		return s.storage.GetUserActivityByMonth(ctx, dto)
+	case request.GroupByWeek:
+		return s.storage.GetUserActivityByWeek(ctx, dto)
+	case request.GroupByYear:
+		return s.storage.GetUserActivityByYear(ctx, dto)
+	case request.GroupByWeekday:
+		return s.storage.GetUserActivityByWeekday(ctx, dto)
+	case request.GroupByHour:
+		return s.storage.GetUserActivityByHour(ctx, dto)
 	}
 
 	return s.storage.GetUserActivityByDate(ctx, dto)
 }
+
+// reportDays returns the timezone of the report, campus by default,
+// and the days of the report where empty ones are today in that timezone
+func (s *service) reportDays(loc *time.Location, from, to time.Time) (*time.Location, time.Time, time.Time) {
+	if loc == nil {
+		loc = s.cfg.Location
+	}
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if from.IsZero() {
+		from = today
+	}
+	if to.IsZero() {
+		to = today
+	}
+
+	return loc, from, to
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+	"time"
+)
+
+// RunUserSync syncs users with the directory every UserSyncInterval until ctx is done.
+func (s *service) RunUserSync(ctx context.Context) {
+	if s.cfg.UserSource == nil || s.cfg.UserSyncInterval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.UserSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		if report, err := s.SyncUsers(ctx); err != nil {
+			log.Printf("user sync: %s", err)
+		} else {
+			log.Printf("user sync: total %d, created %d, updated %d, removed %d, restored %d, invalid %d",
+				report.Total, len(report.Created), len(report.Updated), len(report.Removed), len(report.Restored), report.Invalid)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncUsers reconciles users with the directory, the report is saved even if sync failed.
+func (s *service) SyncUsers(ctx context.Context) (*response.UserSync, error) {
+	if s.cfg.UserSource == nil {
+		return nil, &response.ErrBadReq{Message: "user directory is not configured"}
+	}
+
+	dto := domain.UserSync{
+		Source:    s.cfg.UserSource.Name(),
+		StartedAt: time.Now(),
+	}
+
+	users, err := s.cfg.UserSource.Users(ctx)
+	if err == nil && len(users) == 0 {
+		// do not flag all users as removed because of broken export
+		err = errors.New("directory has no users")
+	}
+	if err != nil {
+		return nil, s.userSyncErr(ctx, &dto, fmt.Errorf("UserSource: %w", err))
+	}
+
+	// skip invalid and duplicated logins, unknown status is not changed
+	seen := make(map[string]bool, len(users))
+	dto.Users = make([]domain.DirectoryUser, 0, len(users))
+
+	for _, user := range users {
+		if (&request.User{Name: user.Login}).Validate() != nil || seen[user.Login] {
+			dto.Invalid++
+			continue
+		}
+		seen[user.Login] = true
+		if !request.IsUserStatus(user.Status) {
+			user.Status = ""
+		}
+		dto.Users = append(dto.Users, user)
+	}
+
+	report, err := s.storage.SyncUsers(ctx, &dto)
+	if err != nil {
+		return nil, s.userSyncErr(ctx, &dto, fmt.Errorf("SyncUsers: %w", err))
+	}
+
+	return report, nil
+}
+
+// userSyncErr saves the report of failed sync and returns err
+func (s *service) userSyncErr(ctx context.Context, dto *domain.UserSync, err error) error {
+	if err2 := s.storage.SaveUserSyncError(ctx, dto, err); err2 != nil {
+		log.Printf("user sync: SaveUserSyncError: %s", err2)
+	}
+	return err
+}
+
+func (s *service) GetUserSyncs(ctx context.Context) ([]response.UserSync, error) {
+	return s.storage.GetUserSyncs(ctx)
+}
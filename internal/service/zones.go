@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"session_manager/internal/domain"
+	"session_manager/internal/domain/request"
+	"session_manager/internal/domain/response"
+	"sort"
+)
+
+// NoZone groups computers without explicit zone and not matched by any pattern
+const NoZone = "unassigned"
+
+type zoneMatcher struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+func (s *service) GetZones(ctx context.Context) ([]response.Zone, error) {
+	return s.storage.GetZones(ctx)
+}
+
+func (s *service) SetZone(ctx context.Context, dto *domain.Zone) error {
+	return s.storage.SetZone(ctx, dto)
+}
+
+func (s *service) DeleteZone(ctx context.Context, name string) error {
+	return s.storage.DeleteZone(ctx, name)
+}
+
+// GetZoneDashboard returns occupancy of every zone: not retired seats, seats in maintenance,
+// online sessions and free seats.
+func (s *service) GetZoneDashboard(ctx context.Context) ([]response.ZoneOccupancy, error) {
+	zones, err := s.storage.GetZones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetZones: %w", err)
+	}
+
+	matchers := make([]zoneMatcher, 0, len(zones))
+	for _, zone := range zones {
+		if zone.ComputerPattern == "" {
+			continue
+		}
+		pattern, err := s.namePattern(zone.ComputerPattern)
+		if err != nil {
+			return nil, fmt.Errorf("zone %s: comp_pattern: %w", zone.Name, err)
+		}
+		matchers = append(matchers, zoneMatcher{name: zone.Name, pattern: pattern})
+	}
+
+	computers, err := s.storage.GetFloorComputers(ctx, &domain.FloorFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("GetFloorComputers: %w", err)
+	}
+
+	sessions, err := s.GetOnlineDashboard(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetOnlineDashboard: %w", err)
+	}
+
+	occupancy := make(map[string]*response.ZoneOccupancy, len(zones)+1)
+	get := func(name string) *response.ZoneOccupancy {
+		zone, ok := occupancy[name]
+		if !ok {
+			zone = &response.ZoneOccupancy{Zone: name}
+			occupancy[name] = zone
+		}
+		return zone
+	}
+	// zones without computers are shown too
+	for _, zone := range zones {
+		get(zone.Name)
+	}
+
+	// the seat is taken if at least one session is online on it
+	taken := make(map[string]bool, len(sessions))
+	computerZone := make(map[string]string, len(computers))
+
+	for _, session := range sessions {
+		taken[session.ComputerName] = true
+	}
+
+	for _, computer := range computers {
+		name := computer.Zone
+		if name == "" {
+			name = matchZone(matchers, computer.ComputerName)
+		}
+		computerZone[computer.ComputerName] = name
+
+		zone := get(name)
+		zone.Seats++
+		if computer.Status == request.ComputerStatusMaintenance {
+			zone.Maintenance++
+		} else if !taken[computer.ComputerName] {
+			zone.Free++
+		}
+	}
+
+	for _, session := range sessions {
+		name, ok := computerZone[session.ComputerName]
+		if !ok {
+			name = matchZone(matchers, session.ComputerName)
+		}
+		get(name).Online++
+	}
+
+	dashboard := make([]response.ZoneOccupancy, 0, len(occupancy))
+	for _, zone := range occupancy {
+		dashboard = append(dashboard, *zone)
+	}
+	sort.Slice(dashboard, func(i, j int) bool { return dashboard[i].Zone < dashboard[j].Zone })
+
+	return dashboard, nil
+}
+
+func matchZone(matchers []zoneMatcher, compName string) string {
+	for _, matcher := range matchers {
+		if matcher.pattern.MatchString(compName) {
+			return matcher.name
+		}
+	}
+	return NoZone
+}